@@ -31,3 +31,19 @@ const (
 	AttrInvalid              // Mark the style or attributes invalid
 	AttrNone    AttrMask = 0 // Just normal text.
 )
+
+// UnderlineStyle is the form of line used to underline text.  Terminals
+// that cannot display the requested style will use a plain (solid)
+// underline instead.
+type UnderlineStyle int
+
+// Underline styles.  The values are those used by the terminal in
+// SGR 4:x sequences, but applications must not rely on this.
+const (
+	UnderlineStyleNone UnderlineStyle = iota
+	UnderlineStyleSolid
+	UnderlineStyleDouble
+	UnderlineStyleCurly
+	UnderlineStyleDotted
+	UnderlineStyleDashed
+)
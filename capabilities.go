@@ -0,0 +1,62 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+// Capabilities describes what the terminal has said about itself, in
+// reply to the queries sent by Screen.Probe.  These are often more
+// reliable than the terminal database, as $TERM is frequently wrong, and
+// multiplexers such as tmux may add or remove features.
+//
+// Replies that arrive after Probe returns are still recorded, so the
+// values may change (but only once, shortly after probing).  Terminals
+// that are not probed, such as the Windows console, report nothing.
+type Capabilities struct {
+	// Probed is true if the terminal replied to the probe.  If it is
+	// false, none of the other values are meaningful.
+	Probed bool
+
+	// Class is the conformance level from the Primary Device Attributes
+	// (DA1), for example 62 for a VT220 or 64 for a VT420.  Terminals
+	// emulating a VT100 report 1.
+	Class int
+
+	// Attributes are the extensions from the Primary Device Attributes,
+	// for example 4 for sixel graphics or 22 for ANSI color.
+	Attributes []int
+
+	// TerminalID and Firmware are from the Secondary Device Attributes
+	// (DA2).  Their meaning varies between terminals; xterm for example
+	// reports its patch level as the firmware version.
+	TerminalID int
+	Firmware   int
+
+	// Version is the name and version reported by XTVERSION, for example
+	// "xterm(390)" or "tmux 3.3a".  It is empty if the terminal does not
+	// support the query.
+	Version string
+
+	// Sixel is true if sixel graphics are supported.
+	Sixel bool
+
+	// SyncOutput, BracketedPaste and FocusEvents are true if the terminal
+	// recognizes the modes used for synchronized output, bracketed paste
+	// and focus reporting.
+	SyncOutput     bool
+	BracketedPaste bool
+	FocusEvents    bool
+
+	// KittyKeyboard is true if the kitty keyboard protocol is supported.
+	KittyKeyboard bool
+}
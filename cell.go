@@ -15,17 +15,37 @@
 package tcell
 
 import (
-	runewidth "github.com/mattn/go-runewidth"
+	"unicode/utf8"
 )
 
+// cell holds the contents of a cell, and what was last displayed there.
+// Combining runes are kept apart, in a table shared by all the cells of
+// a buffer, so that a cell holds no slices of its own; it refers to its
+// combining runes by their index in the table, which is 0 for none.
 type cell struct {
 	currMain  rune
-	currComb  []rune
-	currStyle Style
 	lastMain  rune
+	currComb  int32
+	lastComb  int32
+	width     int32
+	currStyle Style
 	lastStyle Style
-	lastComb  []rune
-	width     int
+}
+
+// minCombs is the size of the table of combining runes beyond which we
+// look for entries that are no longer used.
+const minCombs = 1024
+
+// Cell is the content of a single cell, given to SetContents.
+type Cell struct {
+	// Mainc is the primary rune.
+	Mainc rune
+
+	// Combc holds any combining runes, and is usually nil.
+	Combc []rune
+
+	// Style is the style of the cell.
+	Style Style
 }
 
 // CellBuffer represents a two dimensional array of character cells.
@@ -35,9 +55,16 @@ type cell struct {
 //
 // CellBuffer is not thread safe.
 type CellBuffer struct {
-	w     int
-	h     int
-	cells []cell
+	w      int
+	h      int
+	cells  []cell
+	policy *WidthPolicy
+
+	combs   [][]rune         // distinct sequences of combining runes
+	combIdx map[string]int32 // index in combs, by the runes in UTF-8
+	combMax int              // size of combs at which to compact it
+	keybuf  []byte           // reused to build keys of combIdx
+	runebuf []rune           // reused to split clusters into runes
 }
 
 // SetContent sets the contents (primary rune, combining runes,
@@ -46,18 +73,170 @@ func (cb *CellBuffer) SetContent(x int, y int,
 	mainc rune, combc []rune, style Style) {
 
 	if x >= 0 && y >= 0 && x < cb.w && y < cb.h {
+		comb := cb.internComb(combc)
 		c := &cb.cells[(y*cb.w)+x]
 
-		c.currComb = append([]rune{}, combc...)
-
-		if c.currMain != mainc {
-			c.width = runewidth.RuneWidth(mainc)
+		// The width is cached, as it only depends on the runes.
+		if c.currMain != mainc || c.currComb != comb {
+			c.width = int32(cb.widthPolicy().clusterWidth(mainc, combc))
 		}
+		c.currComb = comb
 		c.currMain = mainc
 		c.currStyle = style
 	}
 }
 
+// SetContents sets the contents of a run of cells, starting at x, y and
+// continuing to the right, with cells[i] at column x+i, just as if
+// SetContent were called for each.  Cells past the end of the line are
+// ignored.
+func (cb *CellBuffer) SetContents(x, y int, cells []Cell) {
+	if y < 0 || y >= cb.h {
+		return
+	}
+	for i := range cells {
+		if x+i >= cb.w {
+			break
+		}
+		c := &cells[i]
+		cb.SetContent(x+i, y, c.Mainc, c.Combc, c.Style)
+	}
+}
+
+// PutString sets the contents of cells starting at x, y and continuing
+// to the right, to the grapheme clusters of the string, in the given
+// style.  Each cluster takes one cell, or two if it is wide (the second
+// is then left as it is, as it is hidden).  Clusters past the end of the
+// line are ignored.  It returns the number of columns used.
+func (cb *CellBuffer) PutString(x, y int, s string, style Style) int {
+	if y < 0 || y >= cb.h {
+		return 0
+	}
+	col := x
+	for s != "" && col < cb.w {
+		if c := s[0]; c >= ' ' && c < utf8.RuneSelf &&
+			(len(s) == 1 || s[1] < utf8.RuneSelf) {
+			// Printable ASCII, not followed by anything that could
+			// combine with it, is very common, and easily handled.
+			cb.SetContent(col, y, rune(c), nil, style)
+			s = s[1:]
+			col++
+			continue
+		}
+		var cluster string
+		cluster, s = nextCluster(s)
+		runes := cb.splitRunes(cluster)
+		cb.SetContent(col, y, runes[0], runes[1:], style)
+		width := 1
+		if col >= 0 {
+			width = int(cb.cells[y*cb.w+col].width)
+		}
+		if width < 1 {
+			width = 1
+		}
+		col += width
+	}
+	if col > cb.w {
+		col = cb.w
+	}
+	return col - x
+}
+
+// splitRunes returns the runes of a cluster, in a slice that is reused.
+func (cb *CellBuffer) splitRunes(cluster string) []rune {
+	runes := cb.runebuf[:0]
+	for _, r := range cluster {
+		runes = append(runes, r)
+	}
+	cb.runebuf = runes
+	return runes
+}
+
+// internComb returns the index of the combining runes in the table of
+// them, adding them if they are not yet there.
+func (cb *CellBuffer) internComb(combc []rune) int32 {
+	if len(combc) == 0 {
+		return 0
+	}
+	var enc [utf8.UTFMax]byte
+	key := cb.keybuf[:0]
+	for _, r := range combc {
+		n := utf8.EncodeRune(enc[:], r)
+		key = append(key, enc[:n]...)
+	}
+	cb.keybuf = key
+	if i, ok := cb.combIdx[string(key)]; ok {
+		return i
+	}
+	if len(cb.combs) >= cb.combMax {
+		cb.compactCombs()
+	}
+	i := int32(len(cb.combs))
+	cb.combs = append(cb.combs, append([]rune(nil), combc...))
+	cb.combIdx[string(key)] = i
+	return i
+}
+
+// compactCombs rebuilds the table of combining runes with just those
+// that are in use, for the table would otherwise grow without limit as
+// different sequences are used.
+func (cb *CellBuffer) compactCombs() {
+	combs := [][]rune{nil}
+	idx := make(map[string]int32)
+	remap := make(map[int32]int32)
+	renumber := func(i int32) int32 {
+		if i == 0 {
+			return 0
+		}
+		n, ok := remap[i]
+		if !ok {
+			n = int32(len(combs))
+			combs = append(combs, cb.combs[i])
+			idx[string(cb.combs[i])] = n
+			remap[i] = n
+		}
+		return n
+	}
+	for i := range cb.cells {
+		c := &cb.cells[i]
+		c.currComb = renumber(c.currComb)
+		if c.lastMain != rune(0) {
+			c.lastComb = renumber(c.lastComb)
+		} else {
+			c.lastComb = 0
+		}
+	}
+	cb.combs = combs
+	cb.combIdx = idx
+	cb.combMax = 2 * len(combs)
+	if cb.combMax < minCombs {
+		cb.combMax = minCombs
+	}
+}
+
+// comb returns the combining runes with the given index.
+func (cb *CellBuffer) comb(i int32) []rune {
+	if i == 0 {
+		return nil
+	}
+	return cb.combs[i]
+}
+
+// SetContentString sets the contents of a cell to a grapheme cluster
+// (a base character, and whatever follows it to make up what is seen as
+// a single character, such as combining marks, or the rest of an emoji
+// sequence), with the given style.  The width of the cell is that of the
+// whole cluster.  Only the first cluster of the string is used; an empty
+// string is treated as a space.
+func (cb *CellBuffer) SetContentString(x, y int, cluster string, style Style) {
+	cluster, _ = nextCluster(cluster)
+	if cluster == "" {
+		cluster = " "
+	}
+	runes := cb.splitRunes(cluster)
+	cb.SetContent(x, y, runes[0], runes[1:], style)
+}
+
 // GetContent returns the contents of a character cell, including the
 // primary rune, any combining character runes (which will usually be
 // nil), the style, and the display width in cells.  (The width can be
@@ -69,8 +248,8 @@ func (cb *CellBuffer) GetContent(x, y int) (rune, []rune, Style, int) {
 	var width int
 	if x >= 0 && y >= 0 && x < cb.w && y < cb.h {
 		c := &cb.cells[(y*cb.w)+x]
-		mainc, combc, style = c.currMain, c.currComb, c.currStyle
-		if width = c.width; width == 0 || mainc < ' ' {
+		mainc, combc, style = c.currMain, cb.comb(c.currComb), c.currStyle
+		if width = int(c.width); width == 0 || mainc < ' ' {
 			width = 1
 			mainc = ' '
 		}
@@ -78,6 +257,45 @@ func (cb *CellBuffer) GetContent(x, y int) (rune, []rune, Style, int) {
 	return mainc, combc, style, width
 }
 
+// SetWidthPolicy sets the policy used to determine the width of the
+// contents of each cell.  The widths of all cells are updated, and the
+// cells are invalidated, so that they are all redrawn.  The default is
+// DefaultWidthPolicy().
+func (cb *CellBuffer) SetWidthPolicy(policy WidthPolicy) {
+	overrides := make(map[rune]int, len(policy.Overrides))
+	for r, w := range policy.Overrides {
+		overrides[r] = w
+	}
+	policy.Overrides = overrides
+	cb.policy = &policy
+	for i := range cb.cells {
+		c := &cb.cells[i]
+		c.width = int32(policy.clusterWidth(c.currMain, cb.comb(c.currComb)))
+	}
+	cb.Invalidate()
+}
+
+// WidthPolicy returns the policy used to determine the width of the
+// contents of each cell.
+func (cb *CellBuffer) WidthPolicy() WidthPolicy {
+	p := *cb.widthPolicy()
+	if p.Overrides != nil {
+		overrides := make(map[rune]int, len(p.Overrides))
+		for r, w := range p.Overrides {
+			overrides[r] = w
+		}
+		p.Overrides = overrides
+	}
+	return p
+}
+
+func (cb *CellBuffer) widthPolicy() *WidthPolicy {
+	if cb.policy == nil {
+		return &defaultWidthPolicy
+	}
+	return cb.policy
+}
+
 // Size returns the (width, height) in cells of the buffer.
 func (cb *CellBuffer) Size() (int, int) {
 	return cb.w, cb.h
@@ -106,14 +324,9 @@ func (cb *CellBuffer) Dirty(x, y int) bool {
 		if c.lastStyle != c.currStyle {
 			return true
 		}
-		if len(c.lastComb) != len(c.currComb) {
+		if c.lastComb != c.currComb {
 			return true
 		}
-		for i := range c.lastComb {
-			if c.lastComb[i] != c.currComb[i] {
-				return true
-			}
-		}
 	}
 	return false
 }
@@ -137,6 +350,51 @@ func (cb *CellBuffer) SetDirty(x, y int, dirty bool) {
 	}
 }
 
+// lastMatches returns true if the cell at x, y has the contents last
+// displayed at column lx of the same row.
+func (cb *CellBuffer) lastMatches(x, lx, y int) bool {
+	if x < 0 || lx < 0 || y < 0 || x >= cb.w || lx >= cb.w || y >= cb.h {
+		return false
+	}
+	c := &cb.cells[(y*cb.w)+x]
+	l := &cb.cells[(y*cb.w)+lx]
+	return l.lastMain != rune(0) && l.lastMain == c.currMain &&
+		l.lastComb == c.currComb && l.lastStyle == c.currStyle
+}
+
+// shiftLast moves what was last displayed in row y, from column x to
+// the end of the line, right by n columns (or left, if n is negative),
+// as a terminal does when characters are inserted or deleted.  The
+// columns exposed are marked dirty.
+func (cb *CellBuffer) shiftLast(x, y, n int) {
+	if x < 0 || y < 0 || x >= cb.w || y >= cb.h || n == 0 {
+		return
+	}
+	row := cb.cells[y*cb.w+x : (y+1)*cb.w]
+	if n > 0 {
+		for i := len(row) - 1; i >= 0; i-- {
+			if i >= n {
+				row[i].lastMain = row[i-n].lastMain
+				row[i].lastComb = row[i-n].lastComb
+				row[i].lastStyle = row[i-n].lastStyle
+			} else {
+				row[i].lastMain = rune(0)
+			}
+		}
+		return
+	}
+	n = -n
+	for i := range row {
+		if i+n < len(row) {
+			row[i].lastMain = row[i+n].lastMain
+			row[i].lastComb = row[i+n].lastComb
+			row[i].lastStyle = row[i+n].lastStyle
+		} else {
+			row[i].lastMain = rune(0)
+		}
+	}
+}
+
 // Resize is used to resize the cells array, with different dimensions,
 // while preserving the original contents.  The cells will be invalidated
 // so that they can be redrawn.
@@ -163,6 +421,42 @@ func (cb *CellBuffer) Resize(w, h int) {
 	cb.w = w
 }
 
+// ScrollRegion moves the lines from top to bottom (inclusive) up by n
+// lines, or down if n is negative, as a terminal does when it scrolls.
+// Both the current contents and those last displayed are moved, so that
+// the buffer still matches a display that is scrolled the same way.
+// The lines exposed are filled with blanks in the given style, and
+// marked dirty, so that they will be drawn.
+func (cb *CellBuffer) ScrollRegion(top, bottom, n int, style Style) {
+	if top < 0 {
+		top = 0
+	}
+	if bottom >= cb.h {
+		bottom = cb.h - 1
+	}
+	if top > bottom || n == 0 {
+		return
+	}
+	lines := bottom - top + 1
+	if n >= lines || -n >= lines {
+		n = lines
+	}
+	w := cb.w
+	region := cb.cells[top*w : (bottom+1)*w]
+	var blank []cell
+	if n > 0 {
+		copy(region, region[n*w:])
+		blank = region[(lines-n)*w:]
+	} else {
+		copy(region[-n*w:], region)
+		blank = region[:-n*w]
+	}
+	for i := range blank {
+		c := &blank[i]
+		*c = cell{currMain: ' ', currStyle: style, width: 1}
+	}
+}
+
 // Fill fills the entire cell buffer array with the specified character
 // and style.  Normally choose ' ' to clear the screen.  This API doesn't
 // support combining characters, or characters with a width larger than one.
@@ -170,7 +464,7 @@ func (cb *CellBuffer) Fill(r rune, style Style) {
 	for i := range cb.cells {
 		c := &cb.cells[i]
 		c.currMain = r
-		c.currComb = nil
+		c.currComb = 0
 		c.currStyle = style
 		c.width = 1
 	}
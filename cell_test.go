@@ -0,0 +1,65 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"testing"
+)
+
+func TestCellBufferCombining(t *testing.T) {
+	var cb CellBuffer
+	cb.Resize(10, 2)
+
+	cb.SetContent(0, 0, 'e', []rune{'\u0301'}, StyleDefault)
+	cb.SetDirty(0, 0, false)
+	cb.SetContent(0, 0, 'e', []rune{'\u0301'}, StyleDefault)
+	if cb.Dirty(0, 0) {
+		t.Errorf("Same contents should not be dirty")
+	}
+	cb.SetContent(0, 0, 'e', []rune{'\u0300'}, StyleDefault)
+	if !cb.Dirty(0, 0) {
+		t.Errorf("Different combining runes should be dirty")
+	}
+
+	// Sequences no longer used are dropped, and those still used kept.
+	cb.SetContent(1, 1, 'a', []rune{'\u0308'}, StyleDefault)
+	for i := 0; i < 5000; i++ {
+		cb.SetContent(0, 1, 'x', []rune{0x300 + rune(i%64), 0x300 + rune(i/64)}, StyleDefault)
+	}
+	if len(cb.combs) > 2*minCombs {
+		t.Errorf("Table of combining runes not compacted: %d", len(cb.combs))
+	}
+	if _, combc, _, _ := cb.GetContent(1, 1); string(combc) != "\u0308" {
+		t.Errorf("Combining runes lost: %q", string(combc))
+	}
+	if _, combc, _, _ := cb.GetContent(0, 0); string(combc) != "\u0300" {
+		t.Errorf("Combining runes lost: %q", string(combc))
+	}
+}
+
+func TestCellBufferAllocs(t *testing.T) {
+	var cb CellBuffer
+	cb.Resize(80, 25)
+	combc := []rune{'\u0301'}
+	cells := []Cell{{Mainc: 'e', Combc: combc}, {Mainc: 'x'}}
+	allocs := testing.AllocsPerRun(10, func() {
+		cb.SetContent(0, 0, 'e', combc, StyleDefault)
+		cb.SetContents(0, 1, cells)
+		cb.PutString(0, 2, "log line: é \U0001f44d\U0001f3fd done", StyleDefault)
+	})
+	if allocs != 0 {
+		t.Errorf("Setting contents allocated %v times", allocs)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Runes with special meaning within grapheme clusters.
+const (
+	runeZWJ  = '\u200d' // zero width joiner, used in emoji sequences
+	runeVS15 = '\ufe0e' // variation selector for text presentation
+	runeVS16 = '\ufe0f' // variation selector for emoji presentation
+)
+
+// nextCluster splits the first extended grapheme cluster (what the user
+// thinks of as a single character) from the string, returning it and the
+// rest of the string.  This follows the rules of Unicode Standard Annex
+// #29 closely enough for terminal use: a base character is extended by
+// combining marks, variation selectors, emoji modifiers and tags, by
+// characters joined to it with a ZWJ, by a second regional indicator (to
+// make a flag), and by the jamo that make up a Hangul syllable.
+func nextCluster(s string) (string, string) {
+	if s == "" {
+		return "", ""
+	}
+	if len(s) > 1 && s[0] == '\r' && s[1] == '\n' {
+		return s[:2], s[2:]
+	}
+	base, n := utf8.DecodeRuneInString(s)
+	if base < ' ' || (base >= 0x7f && base < 0xa0) {
+		return s[:n], s[n:]
+	}
+
+	prev := base
+	ri := isRegionalIndicator(base)
+	for n < len(s) {
+		r, l := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case isClusterExtend(r):
+		case prev == runeZWJ && isPictographic(r):
+		case ri && isRegionalIndicator(r):
+			ri = false
+		case hangulJoins(prev, r):
+		default:
+			return s[:n], s[n:]
+		}
+		prev = r
+		n += l
+	}
+	return s, ""
+}
+
+// isClusterExtend returns true if the rune never begins a cluster, but
+// instead extends the one before it.
+func isClusterExtend(r rune) bool {
+	switch {
+	case r == runeZWJ, r == '\u200c':
+		return true
+	case r >= 0xfe00 && r <= 0xfe0f: // variation selectors
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // tags, used for subdivision flags
+		return true
+	case r >= 0xe0100 && r <= 0xe01ef: // more variation selectors
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+// isRegionalIndicator returns true for the letters used in pairs to
+// spell out flags.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isPictographic returns true for runes that can follow a ZWJ in an emoji
+// sequence.  This is an approximation of Extended_Pictographic.
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x2190 && r <= 0x21ff, r >= 0x2300 && r <= 0x23ff:
+		return true
+	case r >= 0x2600 && r <= 0x27bf, r >= 0x2b00 && r <= 0x2bff:
+		return true
+	case r >= 0x1f000 && r <= 0x1faff:
+		return true
+	}
+	return false
+}
+
+// Hangul syllable types, used to keep the jamo of a syllable together.
+const (
+	hangulNone = iota
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+)
+
+func hangulType(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return hangulL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return hangulV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return hangulT
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	}
+	return hangulNone
+}
+
+// hangulJoins returns true if the jamo r continues the syllable that
+// ends with prev.
+func hangulJoins(prev, r rune) bool {
+	switch hangulType(prev) {
+	case hangulL:
+		t := hangulType(r)
+		return t == hangulL || t == hangulV || t == hangulLV || t == hangulLVT
+	case hangulV, hangulLV:
+		t := hangulType(r)
+		return t == hangulV || t == hangulT
+	case hangulT, hangulLVT:
+		return hangulType(r) == hangulT
+	}
+	return false
+}
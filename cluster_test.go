@@ -0,0 +1,52 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"testing"
+)
+
+func TestClusters(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		first string
+		width int
+	}{
+		{"ascii", "ab", "a", 1},
+		{"combining", "e\u0301x", "e\u0301", 1},
+		{"crlf", "\r\nx", "\r\n", 0},
+		{"wide", "世界", "世", 2},
+		{"flag", "\U0001f1fa\U0001f1f8\U0001f1ec\U0001f1e7", "\U0001f1fa\U0001f1f8", 2},
+		{"skin tone", "\U0001f44d\U0001f3fdx", "\U0001f44d\U0001f3fd", 2},
+		{"zwj", "\U0001f468\u200d\U0001f469\u200d\U0001f467!", "\U0001f468\u200d\U0001f469\u200d\U0001f467", 2},
+		{"emoji presentation", "❤\ufe0fx", "❤\ufe0f", 2},
+		{"text presentation", "⌚\ufe0ex", "⌚\ufe0e", 1},
+		{"keycap", "1\ufe0f\u20e3", "1\ufe0f\u20e3", 2},
+		{"hangul", "각ᄀ", "각", 2},
+	}
+	p := &WidthPolicy{}
+	for _, test := range tests {
+		first, rest := nextCluster(test.s)
+		if first != test.first || first+rest != test.s {
+			t.Errorf("%s: bad split %q %q", test.name, first, rest)
+			continue
+		}
+		runes := []rune(first)
+		if w := p.clusterWidth(runes[0], runes[1:]); w != test.width {
+			t.Errorf("%s: bad width %d", test.name, w)
+		}
+	}
+}
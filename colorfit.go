@@ -23,7 +23,16 @@ import (
 // from the palette given.  This is an expensive operation, so results should
 // be cached by the caller.
 func FindColor(c Color, palette []Color) Color {
-	match := ColorDefault
+	if i := findColorIndex(c, palette); i >= 0 {
+		return palette[i]
+	}
+	return ColorDefault
+}
+
+// findColorIndex is like FindColor, but it returns the index of the best
+// match in the palette, or -1 if the palette is empty.
+func findColorIndex(c Color, palette []Color) int {
+	match := -1
 	dist := float64(0)
 	r, g, b := c.RGB()
 	c1 := colorful.Color{
@@ -31,7 +40,7 @@ func FindColor(c Color, palette []Color) Color {
 		G: float64(g) / 255.0,
 		B: float64(b) / 255.0,
 	}
-	for _, d := range palette {
+	for i, d := range palette {
 		r, g, b = d.RGB()
 		c2 := colorful.Color{
 			R: float64(r) / 255.0,
@@ -43,8 +52,8 @@ func FindColor(c Color, palette []Color) Color {
 		if math.IsNaN(nd) {
 			nd = math.Inf(1)
 		}
-		if match == ColorDefault || nd < dist {
-			match = d
+		if match < 0 || nd < dist {
+			match = i
 			dist = nd
 		}
 	}
@@ -19,6 +19,7 @@ package tcell
 import (
 	"errors"
 	"fmt"
+	"image"
 	"os"
 	"strings"
 	"sync"
@@ -41,6 +42,9 @@ type cScreen struct {
 	fini       bool
 	vten       bool
 	truecolor  bool
+	mouseon    bool
+	focuson    bool
+	suspended  bool
 
 	w int
 	h int
@@ -49,9 +53,15 @@ type cScreen struct {
 	ocursor cursorInfo
 	oimode  uint32
 	oomode  uint32
+	otitle  []uint16
 	cells   CellBuffer
+	title   string
+
+	cursorStyle CursorStyle
+	cursorColor Color
 
 	finiOnce sync.Once
+	frame    frameState
 
 	sync.Mutex
 }
@@ -112,6 +122,7 @@ var (
 	procWaitForMultipleObjects     = k32.NewProc("WaitForMultipleObjects")
 	procCreateEvent                = k32.NewProc("CreateEventW")
 	procSetEvent                   = k32.NewProc("SetEvent")
+	procResetEvent                 = k32.NewProc("ResetEvent")
 	procGetConsoleCursorInfo       = k32.NewProc("GetConsoleCursorInfo")
 	procSetConsoleCursorInfo       = k32.NewProc("SetConsoleCursorInfo")
 	procSetConsoleCursorPosition   = k32.NewProc("SetConsoleCursorPosition")
@@ -123,6 +134,8 @@ var (
 	procSetConsoleWindowInfo       = k32.NewProc("SetConsoleWindowInfo")
 	procSetConsoleScreenBufferSize = k32.NewProc("SetConsoleScreenBufferSize")
 	procSetConsoleTextAttribute    = k32.NewProc("SetConsoleTextAttribute")
+	procGetConsoleTitle            = k32.NewProc("GetConsoleTitleW")
+	procSetConsoleTitle            = k32.NewProc("SetConsoleTitleW")
 	procMessageBeep                = u32.NewProc("MessageBeep")
 )
 
@@ -145,6 +158,10 @@ const (
 	vtSetBg      = "\x1b[48;5;%dm"
 	vtSetFgRGB   = "\x1b[38;2;%d;%d;%dm" // RGB
 	vtSetBgRGB   = "\x1b[48;2;%d;%d;%dm" // RGB
+
+	vtCursorStyle      = "\x1b[%d q"
+	vtCursorColorRGB   = "\x1b]12;#%06x\a"
+	vtCursorColorReset = "\x1b]112\a"
 )
 
 // NewConsoleScreen returns a Screen for the Windows console associated
@@ -208,6 +225,7 @@ func (s *cScreen) Init() error {
 	s.getConsoleInfo(&s.oscreen)
 	s.getOutMode(&s.oomode)
 	s.getInMode(&s.oimode)
+	s.getTitle(&s.otitle)
 	s.resize()
 
 	s.fini = false
@@ -230,6 +248,9 @@ func (s *cScreen) Init() error {
 
 	s.clearScreen(s.style)
 	s.hideCursor()
+	if s.title != "" {
+		s.setTitle(s.title)
+	}
 	s.Unlock()
 	go s.scanInput()
 
@@ -242,19 +263,82 @@ func (s *cScreen) CharacterSet() string {
 }
 
 func (s *cScreen) EnableMouse() {
-	s.setInMode(modeResizeEn | modeMouseEn | modeExtndFlg)
+	s.Lock()
+	s.mouseon = true
+	if !s.fini && !s.suspended {
+		s.setInMode(modeResizeEn | modeMouseEn | modeExtndFlg)
+	}
+	s.Unlock()
 }
 
 func (s *cScreen) DisableMouse() {
-	s.setInMode(modeResizeEn | modeExtndFlg)
+	s.Lock()
+	s.mouseon = false
+	if !s.fini && !s.suspended {
+		s.setInMode(modeResizeEn | modeExtndFlg)
+	}
+	s.Unlock()
+}
+
+// EnableFocus enables focus events.  The console always reports
+// these, so we just start delivering them.
+func (s *cScreen) EnableFocus() {
+	s.Lock()
+	s.focuson = true
+	s.Unlock()
+}
+
+func (s *cScreen) DisableFocus() {
+	s.Lock()
+	s.focuson = false
+	s.Unlock()
+}
+
+// EnableKittyKeyboard does nothing, as the console already reports
+// keys unambiguously.
+func (s *cScreen) EnableKittyKeyboard(KeyboardFlags) {}
+
+func (s *cScreen) DisableKittyKeyboard() {}
+
+// EnableModifyOtherKeys does nothing, for the same reason.
+func (s *cScreen) EnableModifyOtherKeys() {}
+
+func (s *cScreen) DisableModifyOtherKeys() {}
+
+// ShowImage is not supported by the console.
+func (s *cScreen) ShowImage(x, y, w, h int, img image.Image) error {
+	return ErrNotSupported
+}
+
+func (s *cScreen) ClearImages() {}
+
+// Probe returns ErrNotSupported, as the console cannot be asked.
+func (s *cScreen) Probe() error {
+	return ErrNotSupported
 }
 
+// Capabilities returns nothing, as the console is not probed.
+func (s *cScreen) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (s *cScreen) QueryDefaultColors() (Color, Color, error) {
+	return ColorDefault, ColorDefault, ErrNotSupported
+}
+
+func (s *cScreen) QueryPalette() ([]Color, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *cScreen) SetPaletteColor(int, Color) {}
+
 func (s *cScreen) Fini() {
 	s.finiOnce.Do(s.finish)
 }
 
 func (s *cScreen) finish() {
 	s.Lock()
+	s.resetCursorStyle()
 	s.style = StyleDefault
 	s.curx = -1
 	s.cury = -1
@@ -271,6 +355,7 @@ func (s *cScreen) finish() {
 	procSetConsoleTextAttribute.Call(
 		uintptr(s.out),
 		uintptr(s.mapStyle(StyleDefault)))
+	s.restoreTitle()
 
 	close(s.quit)
 	procSetEvent.Call(uintptr(s.cancelflag))
@@ -281,6 +366,71 @@ func (s *cScreen) finish() {
 	syscall.Close(s.out)
 }
 
+func (s *cScreen) Suspend() error {
+	s.Lock()
+	if s.fini || s.suspended {
+		s.Unlock()
+		return nil
+	}
+	s.suspended = true
+	s.Unlock()
+
+	// Stop the input scanner, so that it does not steal input
+	// meant for whatever program takes over the console.
+	procSetEvent.Call(uintptr(s.cancelflag))
+	<-s.scandone
+
+	s.resetCursorStyle()
+	s.setCursorInfo(&s.ocursor)
+	s.setInMode(s.oimode)
+	s.setOutMode(s.oomode)
+	s.restoreTitle()
+	return nil
+}
+
+func (s *cScreen) Resume() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.fini || !s.suspended {
+		return nil
+	}
+	s.suspended = false
+
+	procResetEvent.Call(uintptr(s.cancelflag))
+	s.scandone = make(chan struct{})
+
+	if s.mouseon {
+		s.setInMode(modeResizeEn | modeMouseEn | modeExtndFlg)
+	} else {
+		s.setInMode(modeResizeEn | modeExtndFlg)
+	}
+	if s.vten {
+		s.setOutMode(modeVtOutput | modeNoAutoNL | modeCookedOut)
+	} else {
+		s.setOutMode(0)
+	}
+
+	s.hideCursor()
+	if s.title != "" {
+		s.setTitle(s.title)
+	}
+	if s.vten && s.cursorStyle != CursorStyleDefault {
+		s.emitVtString(fmt.Sprintf(vtCursorStyle, int(s.cursorStyle)))
+	}
+	if s.vten && s.cursorColor != ColorDefault {
+		s.sendCursorColor()
+	}
+	s.resize()
+	s.clear = true
+	if !s.frame.deferDraw(frameShow) {
+		s.draw()
+		s.doCursor()
+	}
+
+	go s.scanInput()
+	return nil
+}
+
 func (s *cScreen) PostEventWait(ev Event) {
 	s.evch <- ev
 }
@@ -334,7 +484,57 @@ func (s *cScreen) showCursor() {
 	if s.vten {
 		s.emitVtString(vtShowCursor)
 	} else {
-		s.setCursorInfo(&cursorInfo{size: 100, visible: 1})
+		// The legacy console can only vary the height of the cursor.
+		size := uint32(100)
+		switch s.cursorStyle {
+		case CursorStyleBlinkingUnderline, CursorStyleSteadyUnderline,
+			CursorStyleBlinkingBar, CursorStyleSteadyBar:
+			size = 25
+		}
+		s.setCursorInfo(&cursorInfo{size: size, visible: 1})
+	}
+}
+
+func (s *cScreen) SetCursorStyle(cs CursorStyle) {
+	s.Lock()
+	s.cursorStyle = cs
+	if !s.fini && !s.suspended {
+		if s.vten {
+			s.emitVtString(fmt.Sprintf(vtCursorStyle, int(cs)))
+		} else {
+			s.doCursor()
+		}
+	}
+	s.Unlock()
+}
+
+func (s *cScreen) SetCursorColor(c Color) {
+	s.Lock()
+	s.cursorColor = c
+	if !s.fini && !s.suspended && s.vten {
+		s.sendCursorColor()
+	}
+	s.Unlock()
+}
+
+func (s *cScreen) sendCursorColor() {
+	if v := s.cursorColor.Hex(); v >= 0 {
+		s.emitVtString(fmt.Sprintf(vtCursorColorRGB, v))
+	} else {
+		s.emitVtString(vtCursorColorReset)
+	}
+}
+
+// resetCursorStyle restores the default cursor shape and color.
+func (s *cScreen) resetCursorStyle() {
+	if !s.vten {
+		return
+	}
+	if s.cursorStyle != CursorStyleDefault {
+		s.emitVtString(fmt.Sprintf(vtCursorStyle, int(CursorStyleDefault)))
+	}
+	if s.cursorColor != ColorDefault {
+		s.emitVtString(vtCursorColorReset)
 	}
 }
 
@@ -382,7 +582,7 @@ const (
 	mouseEvent  uint16 = 2
 	resizeEvent uint16 = 4
 	menuEvent   uint16 = 8  // don't use
-	focusEvent  uint16 = 16 // don't use
+	focusEvent  uint16 = 16
 )
 
 type mouseRecord struct {
@@ -677,6 +877,14 @@ func (s *cScreen) getConsoleInput() error {
 			rrec.y = geti16(rec.data[2:])
 			s.PostEventWait(NewEventResize(int(rrec.x), int(rrec.y)))
 
+		case focusEvent:
+			s.Lock()
+			focuson := s.focuson
+			s.Unlock()
+			if focuson {
+				s.PostEventWait(NewEventFocus(geti32(rec.data[0:]) != 0, ""))
+			}
+
 		default:
 		}
 	default:
@@ -792,6 +1000,43 @@ func (s *cScreen) SetContent(x, y int, mainc rune, combc []rune, style Style) {
 	s.Unlock()
 }
 
+func (s *cScreen) SetWidthPolicy(policy WidthPolicy) {
+	s.Lock()
+	s.cells.SetWidthPolicy(policy)
+	s.Unlock()
+}
+
+func (s *cScreen) WidthPolicy() WidthPolicy {
+	s.Lock()
+	defer s.Unlock()
+	return s.cells.WidthPolicy()
+}
+
+func (s *cScreen) SetContentString(x, y int, cluster string, style Style) {
+	s.Lock()
+	if !s.fini {
+		s.cells.SetContentString(x, y, cluster, style)
+	}
+	s.Unlock()
+}
+
+func (s *cScreen) SetContents(x, y int, cells []Cell) {
+	s.Lock()
+	if !s.fini {
+		s.cells.SetContents(x, y, cells)
+	}
+	s.Unlock()
+}
+
+func (s *cScreen) PutString(x, y int, str string, style Style) int {
+	s.Lock()
+	defer s.Unlock()
+	if s.fini {
+		return 0
+	}
+	return s.cells.PutString(x, y, str, style)
+}
+
 func (s *cScreen) GetContent(x, y int) (rune, []rune, Style, int) {
 	s.Lock()
 	mainc, combc, style, width := s.cells.GetContent(x, y)
@@ -912,24 +1157,59 @@ func (s *cScreen) draw() {
 
 func (s *cScreen) Show() {
 	s.Lock()
-	if !s.fini {
+	if !s.frame.deferDraw(frameShow) {
+		s.show()
+	}
+	s.Unlock()
+}
+
+func (s *cScreen) show() {
+	if !s.fini && !s.suspended {
 		s.hideCursor()
 		s.resize()
 		s.draw()
 		s.doCursor()
 	}
-	s.Unlock()
 }
 
 func (s *cScreen) Sync() {
 	s.Lock()
-	if !s.fini {
+	if !s.frame.deferDraw(frameSync) {
+		s.sync()
+	}
+	s.Unlock()
+}
+
+func (s *cScreen) sync() {
+	if !s.fini && !s.suspended {
 		s.cells.Invalidate()
 		s.hideCursor()
 		s.resize()
 		s.draw()
 		s.doCursor()
 	}
+}
+
+func (s *cScreen) SetMaxFPS(int) {}
+
+func (s *cScreen) ShowNow() {
+	s.Show()
+}
+
+func (s *cScreen) BeginFrame() {
+	s.Lock()
+	s.frame.begin()
+	s.Unlock()
+}
+
+func (s *cScreen) EndFrame() {
+	s.Lock()
+	switch s.frame.end() {
+	case frameShow:
+		s.show()
+	case frameRedraw, frameSync:
+		s.sync()
+	}
 	s.Unlock()
 }
 
@@ -1022,6 +1302,21 @@ func (s *cScreen) Fill(r rune, style Style) {
 	s.Unlock()
 }
 
+func (s *cScreen) ScrollRegion(top, bottom, n int) {
+	s.Lock()
+	defer s.Unlock()
+	if s.fini {
+		return
+	}
+	w, _ := s.cells.Size()
+	s.cells.ScrollRegion(top, bottom, n, s.style)
+	for y := top; y <= bottom; y++ {
+		for x := 0; x < w; x++ {
+			s.cells.SetDirty(x, y, true)
+		}
+	}
+}
+
 func (s *cScreen) clearScreen(style Style) {
 	if s.vten {
 		s.sendVtStyle(style)
@@ -1178,6 +1473,39 @@ func (s *cScreen) SetClipboard(string, string) error {
 	return errors.New("Not supported on Windows")
 }
 
+func (s *cScreen) getTitle(t *[]uint16) {
+	buf := make([]uint16, 1024)
+	n, _, _ := procGetConsoleTitle.Call(
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)))
+	*t = buf[:n]
+}
+
+func (s *cScreen) setTitle(title string) {
+	if t, e := syscall.UTF16PtrFromString(title); e == nil {
+		procSetConsoleTitle.Call(uintptr(unsafe.Pointer(t)))
+	}
+}
+
+func (s *cScreen) restoreTitle() {
+	if s.title != "" {
+		t := append(append([]uint16{}, s.otitle...), 0)
+		procSetConsoleTitle.Call(uintptr(unsafe.Pointer(&t[0])))
+	}
+}
+
+func (s *cScreen) SetTitle(title string) {
+	s.Lock()
+	s.title = title
+	if !s.fini && !s.suspended && s.out != 0 {
+		s.setTitle(title)
+	}
+	s.Unlock()
+}
+
+// SetIconName does nothing, as the console has no separate icon name.
+func (s *cScreen) SetIconName(string) {}
+
 func (s *cScreen) Beep() error {
 	// A simple beep. If the sound card is not available, the sound is generated
 	// using the speaker.
@@ -0,0 +1,84 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+// sendMotion moves the cursor to x, y with the shortest sequence.  If
+// we know where the cursor is, we consider moving it relative to there,
+// which is often much shorter than addressing it absolutely
+// (particularly when moving along a line, or to the next one).
+func (t *tScreen) sendMotion(x, y int) {
+	best := t.tgoto(x, y)
+	if t.cx < 0 || t.cy < 0 || t.cx >= t.w || t.cy >= t.h {
+		// Unknown, or the terminal may be about to wrap.
+		t.TPuts(best)
+		return
+	}
+
+	var vert string
+	ok := true
+	switch dy := y - t.cy; {
+	case dy < 0:
+		vert, ok = t.repeatMotion(t.cuu1, t.cuu, -dy, len(best))
+	case dy > 0:
+		vert, ok = t.repeatMotion(t.cud1, t.cud, dy, len(best))
+	}
+	if !ok {
+		t.TPuts(best)
+		return
+	}
+
+	limit := len(best) - len(vert)
+	var ret, horiz string
+	switch dx := x - t.cx; {
+	case dx < 0:
+		horiz, ok = t.repeatMotion(t.cub1, t.cub, -dx, limit)
+		if t.cr != "" {
+			// Return to the start of the line, and move right.
+			rest, rok := "", true
+			if x > 0 {
+				rest, rok = t.repeatMotion(t.cuf1, t.cuf, x, limit-len(t.cr))
+			}
+			if rok && (!ok || len(t.cr)+len(rest) < len(horiz)) {
+				ret, horiz, ok = t.cr, rest, true
+			}
+		}
+	case dx > 0:
+		horiz, ok = t.repeatMotion(t.cuf1, t.cuf, dx, limit)
+	}
+	if !ok || len(vert)+len(ret)+len(horiz) >= len(best) {
+		t.TPuts(best)
+		return
+	}
+	t.TPuts(vert)
+	t.TPuts(ret)
+	t.TPuts(horiz)
+}
+
+// repeatMotion returns the shorter of the single step motion repeated n
+// times, or the parameterized motion, provided that it is shorter than
+// limit.  It returns false if neither will do.
+func (t *tScreen) repeatMotion(one, param string, n int, limit int) (string, bool) {
+	best := ""
+	ok := false
+	if param != "" {
+		if s := t.tparm(param, n); len(s) < limit {
+			best, ok, limit = s, true, len(s)
+		}
+	}
+	if one != "" && len(one)*n < limit {
+		best, ok = t.repeat(one, n), true
+	}
+	return best, ok
+}
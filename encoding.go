@@ -128,6 +128,12 @@ func GetEncoding(charset string) encoding.Encoding {
 	return nil
 }
 
+// isUTF8 returns true if the encoding is UTF-8, so that text can be sent
+// without transforming it.
+func isUTF8(enc encoding.Encoding) bool {
+	return enc == gencoding.UTF8 || enc == encoding.Nop
+}
+
 func init() {
 	// We always support UTF-8 and ASCII.
 	encodings = make(map[string]encoding.Encoding)
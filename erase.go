@@ -0,0 +1,131 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+// Standard (ECMA-48) sequences to erase, used when terminfo lacks them.
+// There is no fallback for repeating a character (REP), as many terminals
+// that otherwise follow the standard do not support it.
+const (
+	clearEOL   = "\x1b[K"
+	eraseChars = "\x1b[%p1%dX"
+)
+
+// drawRun draws a run of identical cells starting at x, y, by erasing
+// them if they are blank, or by repeating the character, if that is
+// shorter than drawing each cell.  It returns the number of cells drawn,
+// which is zero if the cells should be drawn normally.  In that case it
+// also returns the end of the run, as the cells before it should all be
+// drawn normally; there is no need to look at them again.
+func (t *tScreen) drawRun(x, y int) (int, int) {
+	if len(t.images) > 0 || !t.cells.Dirty(x, y) {
+		return 0, x + 1
+	}
+	mainc, combc, style, width := t.cells.GetContent(x, y)
+	if len(combc) > 0 || width != 1 || mainc < ' ' || mainc >= 0x7f {
+		return 0, x + 1
+	}
+	if style == StyleDefault {
+		style = t.style
+	}
+	erase := mainc == ' ' && (t.el != "" || t.ech != "") && t.canErase(style)
+	if !erase && t.rep == "" {
+		return 0, x + 1
+	}
+
+	// Find the end of the run, and the last cell in it that needs to be
+	// drawn, as we would otherwise draw every cell up to that one.
+	end, last := x+1, x
+	for ; end < t.w; end++ {
+		m, c, s, w := t.cells.GetContent(end, y)
+		if s == StyleDefault {
+			s = t.style
+		}
+		if m != mainc || len(c) > 0 || s != style || w != 1 {
+			break
+		}
+		if t.cells.Dirty(end, y) {
+			last = end
+		}
+	}
+	n := last - x + 1
+
+	if erase {
+		if end == t.w && t.el != "" && len(t.el) < n {
+			t.startRun(x, y, style)
+			t.TPuts(t.el)
+			t.cleanRun(x, end, y)
+			return end - x, end
+		}
+		if t.ech != "" {
+			seq := t.tparm(t.ech, n)
+			// The cursor does not move, so we will likely have to
+			// move it past the erased cells afterwards.
+			cost := len(seq)
+			if last+1 < t.w {
+				cost += len(t.tgoto(last+1, y))
+			}
+			if cost < n {
+				t.startRun(x, y, style)
+				t.TPuts(seq)
+				t.cleanRun(x, last+1, y)
+				return n, end
+			}
+		}
+	}
+	if t.rep != "" {
+		if seq := t.tparm(t.rep, int(mainc), n); len(seq) < n {
+			t.startRun(x, y, style)
+			t.TPuts(seq)
+			t.cleanRun(x, last+1, y)
+			t.cx += n
+			return n, end
+		}
+	}
+	return 0, end
+}
+
+// canErase returns true if blank cells of the given style can be drawn
+// by erasing them.  Erased cells have the background color of the style
+// only if the terminal has background color erase (bce), and otherwise
+// have the default background.  Attributes are not applied to them, so
+// those that show on a blank cell (such as underline) rule it out.
+func (t *tScreen) canErase(style Style) bool {
+	_, bg, attrs := style.Decompose()
+	if attrs&(AttrUnderline|AttrReverse|AttrStrikeThrough) != 0 {
+		return false
+	}
+	if url, _ := style.DecomposeUrl(); url != "" {
+		return false
+	}
+	return t.bce || !bg.Valid()
+}
+
+// startRun moves the cursor to the start of a run and sets its style.
+func (t *tScreen) startRun(x, y int, style Style) {
+	if t.cy != y || t.cx != x {
+		t.goTo(x, y)
+		t.cx = x
+		t.cy = y
+	}
+	t.sendStyle(style)
+	t.sendUrl(style.DecomposeUrl())
+}
+
+// cleanRun marks the cells from x up to end as drawn.
+func (t *tScreen) cleanRun(x, end, y int) {
+	for ; x < end; x++ {
+		t.cells.SetDirty(x, y, false)
+	}
+}
@@ -48,6 +48,19 @@ var (
 	// ErrEventQFull indicates that the event queue is full, and
 	// cannot accept more events.
 	ErrEventQFull = errors.New("event queue full")
+
+	// ErrNotSupported indicates that the terminal lacks a capability
+	// that is required for the requested operation.
+	ErrNotSupported = errors.New("operation not supported by terminal")
+
+	// ErrOffScreen indicates that a position is off the top or left of
+	// the screen, where nothing can be drawn.
+	ErrOffScreen = errors.New("position is off the screen")
+
+	// ErrInputNotStopped indicates that reading from the terminal could
+	// not be stopped, when the screen was suspended, so some input meant
+	// for another program may yet be read.
+	ErrInputNotStopped = errors.New("terminal input not stopped")
 )
 
 // An EventError is an event representing some sort of error, and carries
@@ -68,3 +68,36 @@ func TestMouseEvents(t *testing.T) {
 		t.Errorf("Modifiers should be control")
 	}
 }
+
+func TestFocusEvents(t *testing.T) {
+
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	s.EnableFocus()
+	s.InjectFocus(false)
+	evch := make(chan Event)
+	var ef *EventFocus
+	done := false
+	go eventLoop(s, evch)
+
+	for !done {
+		select {
+		case ev := <-evch:
+			if evf, ok := ev.(*EventFocus); ok {
+				ef = evf
+				done = true
+			}
+			continue
+		case <-time.After(time.Second):
+			done = true
+		}
+	}
+
+	if ef == nil {
+		t.Fatalf("No focus event")
+	}
+	if ef.Focused() {
+		t.Errorf("Should not be focused")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"time"
+)
+
+// EventFocus is sent when the window containing the screen gains or
+// loses the input focus.  These are only reported after EnableFocus
+// has been called.
+type EventFocus struct {
+	t       time.Time
+	focused bool
+	esc     string
+}
+
+// NewEventFocus creates an EventFocus, indicating whether the window
+// is now focused.
+func NewEventFocus(focused bool, esc string) *EventFocus {
+	return &EventFocus{t: time.Now(), focused: focused, esc: esc}
+}
+
+// When returns the time when the Event was created.
+func (ev *EventFocus) When() time.Time {
+	return ev.t
+}
+
+// Focused returns true if the window gained focus, and false if it
+// lost it.
+func (ev *EventFocus) Focused() bool {
+	return ev.focused
+}
+
+func (ev *EventFocus) EscSeq() string {
+	return ev.esc
+}
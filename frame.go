@@ -0,0 +1,141 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"time"
+)
+
+// Drawing that may be deferred until the end of a frame, from the least
+// to the most thorough.  Each includes what comes before it.
+const (
+	frameNone   = iota
+	frameShow   // draw the cells that changed, as by Show
+	frameRedraw // draw every cell, as after a resize
+	frameSync   // clear the display and draw every cell, as by Sync
+)
+
+// frameState tracks the frames begun with BeginFrame, and the drawing
+// deferred until they have all ended.  It is protected by the lock of
+// the screen it belongs to.
+type frameState struct {
+	frames  int // number of frames begun and not yet ended
+	pending int // the drawing deferred, such as frameShow
+}
+
+func (f *frameState) begin() {
+	f.frames++
+}
+
+// end ends a frame.  If it was the last one, it returns the drawing that
+// was deferred, which must now be done, and otherwise frameNone.
+func (f *frameState) end() int {
+	if f.frames == 0 {
+		return frameNone
+	}
+	if f.frames--; f.frames > 0 {
+		return frameNone
+	}
+	pending := f.pending
+	f.pending = frameNone
+	return pending
+}
+
+// deferDraw returns true if a frame is in progress, in which case the
+// drawing is recorded to be done when it ends, rather than now.
+func (f *frameState) deferDraw(draw int) bool {
+	if f.frames == 0 {
+		return false
+	}
+	if draw > f.pending {
+		f.pending = draw
+	}
+	return true
+}
+
+func (t *tScreen) SetMaxFPS(fps int) {
+	t.Lock()
+	if fps > 0 {
+		t.interval = time.Second / time.Duration(fps)
+	} else {
+		t.interval = 0
+	}
+	// Anything already scheduled is rescheduled to suit the new rate.
+	if t.cancelShow() {
+		t.scheduleShow()
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) ShowNow() {
+	t.Lock()
+	if !t.frame.deferDraw(frameShow) {
+		t.cancelShow()
+		t.show()
+	}
+	t.Unlock()
+}
+
+// scheduleShow draws the cells that changed, as Show does.  If the frame
+// rate is limited, the drawing is instead left to the main loop, no
+// sooner than one frame interval after the last, and changes made in the
+// meantime are drawn with it.
+func (t *tScreen) scheduleShow() {
+	if t.interval == 0 {
+		t.show()
+		return
+	}
+	if t.drawpend {
+		return
+	}
+	t.drawpend = true
+	delay := t.interval - time.Since(t.lastdraw)
+	if delay < 0 {
+		delay = 0
+	}
+	t.drawat = time.Now().Add(delay)
+	t.drawtimer.Reset(delay)
+}
+
+// cancelShow cancels the drawing scheduled by scheduleShow, returning
+// true if there was any.
+func (t *tScreen) cancelShow() bool {
+	if !t.drawpend {
+		return false
+	}
+	t.drawpend = false
+	if !t.drawtimer.Stop() {
+		select {
+		case <-t.drawtimer.C:
+		default:
+		}
+	}
+	return true
+}
+
+// drawScheduled is called by the main loop to do the drawing scheduled
+// by scheduleShow.  The timer may have fired for drawing since canceled
+// (the main loop can receive from it before we stop it), so that is
+// ignored unless it is time for the drawing now scheduled.
+func (t *tScreen) drawScheduled() {
+	t.Lock()
+	if t.drawpend && !time.Now().Before(t.drawat) {
+		t.drawpend = false
+		if !t.frame.deferDraw(frameShow) {
+			t.show()
+		}
+	}
+	t.Unlock()
+}
@@ -0,0 +1,347 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// imageProtocol is the means by which images are sent to the terminal.
+type imageProtocol int
+
+const (
+	imageNone imageProtocol = iota
+	imageKitty
+	imageSixel
+)
+
+// Kitty graphics protocol.  Image data is sent once, as PNG, and then
+// placed (scaled to fill a number of cells) as often as needed.
+// See https://sw.kovidgoyal.net/kitty/graphics-protocol/
+const (
+	kittyImageChunk  = 4096
+	kittyImageStart  = "\x1b_Ga=t,f=100,i=%d,q=2,m=%d;%s\x1b\\"
+	kittyImageMore   = "\x1b_Gm=%d;%s\x1b\\"
+	kittyImagePlace  = "\x1b_Ga=p,i=%d,p=1,c=%d,r=%d,C=1,q=2\x1b\\"
+	kittyImageDelete = "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\"
+)
+
+// Sixel graphics.  The image is drawn as pixels at the cursor.  We need
+// to know the size of a cell in pixels to scale it, and ask the terminal
+// for that (CSI 16 t), falling back to a common size if it does not say.
+const (
+	sixelStart     = "\x1bP0;1;0q\"1;1;%d;%d"
+	sixelEnd       = "\x1b\\"
+	cellSizeQuery  = "\x1b[16t"
+	defCellWidth   = 10
+	defCellHeight  = 20
+	sixelMaxColors = 256
+)
+
+// tImage is an image placed on the terminal screen.
+type tImage struct {
+	x, y  int
+	w, h  int
+	id    int
+	data  string // kitty image data, or sixel sequence
+	sent  bool   // true if the kitty image data has been sent
+	shown bool   // true if the image is on the screen
+}
+
+// covers returns true if the cell at x, y is covered by the image.
+func (im *tImage) covers(x, y int) bool {
+	return x >= im.x && x < im.x+im.w && y >= im.y && y < im.y+im.h
+}
+
+// detectImages chooses the image protocol.  TCELL_IMAGES may be set to
+// kitty, sixel or disable to override the guess, which is based on
+// the environment that the terminals we know about set, and later on
+// the replies to the probe.
+func (t *tScreen) detectImages() {
+	t.imgfixed = true
+	switch os.Getenv("TCELL_IMAGES") {
+	case "kitty":
+		t.imgproto = imageKitty
+		return
+	case "sixel":
+		t.imgproto = imageSixel
+		return
+	case "disable":
+		return
+	}
+	t.imgfixed = false
+	if !t.isAnsi() {
+		return
+	}
+	name := t.ti.Name
+	switch {
+	case name == "xterm-kitty", strings.HasPrefix(name, "xterm-ghostty"),
+		os.Getenv("KITTY_WINDOW_ID") != "",
+		os.Getenv("TERM_PROGRAM") == "WezTerm":
+		t.imgproto = imageKitty
+	case strings.HasPrefix(name, "foot"), strings.HasPrefix(name, "mlterm"),
+		strings.Contains(name, "sixel"):
+		t.imgproto = imageSixel
+	}
+}
+
+func (t *tScreen) ShowImage(x, y, w, h int, img image.Image) error {
+	t.Lock()
+	proto := t.imgproto
+	inline := t.inline
+	cellw, cellh := t.cellw, t.cellh
+	t.Unlock()
+
+	if proto == imageNone || inline > 0 {
+		return ErrNotSupported
+	}
+	if x < 0 || y < 0 {
+		return ErrOffScreen
+	}
+	if w <= 0 || h <= 0 || img.Bounds().Empty() {
+		return nil
+	}
+
+	// Encoding large images takes a while, so it is done without the
+	// lock, leaving the screen free for other goroutines meanwhile.
+	data := &bytes.Buffer{}
+	sixel := ""
+	switch proto {
+	case imageKitty:
+		if e := png.Encode(data, img); e != nil {
+			return e
+		}
+	case imageSixel:
+		sixel = sixelEncode(img, w*cellw, h*cellh)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if t.imgproto != proto {
+		return ErrNotSupported
+	}
+	t.imageid++
+	im := &tImage{x: x, y: y, w: w, h: h, id: t.imageid}
+	switch proto {
+	case imageKitty:
+		im.data = kittyImageData(im.id, data.Bytes())
+	case imageSixel:
+		im.data = sixel
+	}
+	t.images = append(t.images, im)
+	return nil
+}
+
+func (t *tScreen) ClearImages() {
+	t.Lock()
+	t.clearImages()
+	t.Unlock()
+}
+
+// clearImages removes all images, arranging for the cells beneath them
+// to be redrawn.
+func (t *tScreen) clearImages() {
+	if !t.fini && !t.suspended {
+		t.deleteImages()
+	}
+	for _, im := range t.images {
+		for y := im.y; y < im.y+im.h; y++ {
+			for x := im.x; x < im.x+im.w; x++ {
+				t.cells.SetDirty(x, y, true)
+			}
+		}
+	}
+	t.images = nil
+}
+
+// deleteImages removes kitty images from the terminal, so that they
+// do not linger after we are done with the terminal.  (Sixel images are
+// just pixels, so there is nothing to remove; they are drawn over.)
+func (t *tScreen) deleteImages() {
+	for _, im := range t.images {
+		if im.sent {
+			t.writeString(fmt.Sprintf(kittyImageDelete, im.id))
+		}
+		im.sent = false
+		im.shown = false
+	}
+}
+
+// imageFits returns true if the image can be drawn on the screen.
+func (t *tScreen) imageFits(im *tImage) bool {
+	if t.imgproto == imageSixel {
+		// Drawing on the last line would scroll the screen.
+		return im.x < t.w && im.y+im.h < t.h
+	}
+	return im.x < t.w && im.y < t.h
+}
+
+// imageCovers returns true if the cell at x, y is beneath an image.
+func (t *tScreen) imageCovers(x, y int) bool {
+	for _, im := range t.images {
+		if im.covers(x, y) && t.imageFits(im) {
+			return true
+		}
+	}
+	return false
+}
+
+// drawImages draws any images not already on the screen.
+func (t *tScreen) drawImages() {
+	for _, im := range t.images {
+		if im.shown || !t.imageFits(im) {
+			continue
+		}
+		t.goTo(im.x, im.y)
+		switch t.imgproto {
+		case imageKitty:
+			if !im.sent {
+				t.writeString(im.data)
+				im.sent = true
+			}
+			t.writeString(fmt.Sprintf(kittyImagePlace, im.id, im.w, im.h))
+		case imageSixel:
+			t.writeString(im.data)
+		}
+		im.shown = true
+		t.cx = -1
+		t.cy = -1
+	}
+}
+
+// kittyImageData returns the sequences to send PNG data for an image.
+func kittyImageData(id int, data []byte) string {
+	enc := base64.StdEncoding.EncodeToString(data)
+	sb := &strings.Builder{}
+	for first := true; first || len(enc) > 0; first = false {
+		chunk := enc
+		if len(chunk) > kittyImageChunk {
+			chunk = chunk[:kittyImageChunk]
+		}
+		enc = enc[len(chunk):]
+		more := 0
+		if len(enc) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(sb, kittyImageStart, id, more, chunk)
+		} else {
+			fmt.Fprintf(sb, kittyImageMore, more, chunk)
+		}
+	}
+	return sb.String()
+}
+
+// sixelEncode returns the sixel sequence for the image, scaled to the
+// given size in pixels.  Colors are reduced to a fixed palette, and
+// transparent pixels are left untouched.
+func sixelEncode(img image.Image, pw, ph int) string {
+	// Scale, using the nearest pixel.
+	src := img.Bounds()
+	scaled := image.NewNRGBA(image.Rect(0, 0, pw, ph))
+	for y := 0; y < ph; y++ {
+		sy := src.Min.Y + y*src.Dy()/ph
+		for x := 0; x < pw; x++ {
+			sx := src.Min.X + x*src.Dx()/pw
+			scaled.Set(x, y, img.At(sx, sy))
+		}
+	}
+	pal := palette.Plan9[:sixelMaxColors]
+	pimg := image.NewPaletted(scaled.Bounds(), pal)
+	draw.FloydSteinberg.Draw(pimg, pimg.Bounds(), scaled, image.Point{})
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, sixelStart, pw, ph)
+	var used [sixelMaxColors]bool
+	for _, c := range pimg.Pix {
+		used[c] = true
+	}
+	for i, c := range pal {
+		if !used[i] {
+			continue
+		}
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(sb, "#%d;2;%d;%d;%d", i,
+			r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	// Each band is six pixels high.  For each color in the band, we
+	// emit a row of sixels, returning to the start of the band between
+	// them, and then move to the next band.
+	rows := make(map[uint8][]byte)
+	var order []uint8
+	for y0 := 0; y0 < ph; y0 += 6 {
+		for _, c := range order {
+			delete(rows, c)
+		}
+		order = order[:0]
+		for i := 0; i < 6 && y0+i < ph; i++ {
+			for x := 0; x < pw; x++ {
+				if scaled.NRGBAAt(x, y0+i).A < 0x80 {
+					continue
+				}
+				c := pimg.ColorIndexAt(x, y0+i)
+				row, ok := rows[c]
+				if !ok {
+					row = make([]byte, pw)
+					rows[c] = row
+					order = append(order, c)
+				}
+				row[x] |= 1 << uint(i)
+			}
+		}
+		for n, c := range order {
+			if n > 0 {
+				sb.WriteByte('$')
+			}
+			fmt.Fprintf(sb, "#%d", c)
+			sixelRow(sb, rows[c])
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString(sixelEnd)
+	return sb.String()
+}
+
+// sixelRow writes a row of sixels, compressing runs.  Trailing empty
+// sixels are dropped.
+func sixelRow(sb *strings.Builder, row []byte) {
+	for len(row) > 0 && row[len(row)-1] == 0 {
+		row = row[:len(row)-1]
+	}
+	for i := 0; i < len(row); {
+		n := 1
+		for i+n < len(row) && row[i+n] == row[i] {
+			n++
+		}
+		ch := row[i] + '?'
+		if n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, ch)
+		} else {
+			for j := 0; j < n; j++ {
+				sb.WriteByte(ch)
+			}
+		}
+		i += n
+	}
+}
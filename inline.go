@@ -0,0 +1,192 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"strings"
+)
+
+// InlineScreen is a Screen that occupies a fixed number of lines at the
+// position of the cursor when it is initialized, rather than taking over
+// the entire terminal.  The rest of the terminal, including the shell
+// prompt above it, is left intact.  When the screen is finalized, the
+// last contents drawn are left in place (and hence in the scrollback),
+// and the cursor is positioned on the line following them.
+//
+// The Size of an InlineScreen is the width of the terminal, and the
+// number of lines requested (or the height of the terminal, if that is
+// smaller).  Mouse coordinates are reported relative to the terminal,
+// not to the inline region, so applications using an InlineScreen
+// should generally not enable the mouse.
+type InlineScreen interface {
+	Screen
+
+	// Println prints the text on the terminal above the inline region,
+	// followed by a newline.  The region is moved down to make room,
+	// scrolling the terminal if necessary, and is then redrawn.  The
+	// text may contain multiple lines.  This is useful for logging
+	// results (for example the items a user has chosen) that should
+	// remain visible after the screen is finalized.  Tabs are expanded
+	// to spaces, with tab stops every eight columns, and other control
+	// characters (C0 and C1, such as escape and CSI, and DEL) are
+	// removed, so that the text cannot disturb the terminal.
+	Println(text string)
+}
+
+// tabStop is the distance between the tab stops used by Println.
+const tabStop = 8
+
+// NewInlineTerminfoScreen returns an InlineScreen that uses the given
+// number of lines.  The tty and term arguments are as for
+// NewTerminfoScreenFromTty, so they may be nil and empty to use the
+// controlling terminal and $TERM.  ErrNotSupported is returned if the
+// terminal lacks the relative cursor motions needed for inline use.
+func NewInlineTerminfoScreen(tty Tty, term string, lines int) (InlineScreen, error) {
+	t, e := newTScreen(tty, term)
+	if e != nil {
+		return nil, e
+	}
+	if t.cuf == "" || t.ed == "" || (t.cuu == "" && t.ti.CursorUp1 == "") {
+		return nil, ErrNotSupported
+	}
+	if lines < 1 {
+		lines = 1
+	}
+	t.inline = lines
+	return t, nil
+}
+
+// inlineHeight returns the height of the inline region on a terminal
+// with the given number of lines.
+func (t *tScreen) inlineHeight(h int) int {
+	if h > 0 && h < t.inline {
+		return h
+	}
+	return t.inline
+}
+
+// reserve makes room for the inline region at the cursor, scrolling the
+// terminal if needed, and leaves the cursor at the top left of the
+// (now erased) region.
+func (t *tScreen) reserve() {
+	h := t.inline
+	if _, th, e := t.getWinSize(); e == nil {
+		h = t.inlineHeight(th)
+	}
+	t.TPuts(t.ti.AttrOff)
+	t.curstyle = styleInvalid
+	t.writeString("\r" + strings.Repeat("\n", h-1))
+	t.moveUp(h - 1)
+	t.irow = 0
+	t.TPuts(t.ed)
+}
+
+// release moves the cursor to the start of the line just below the
+// inline region, leaving the region's contents intact.
+func (t *tScreen) release() {
+	t.goTo(0, t.h-1)
+	t.writeString("\r\n")
+	t.irow = 0
+}
+
+// goTo moves the cursor to the given location.  In inline mode, only
+// relative motions are used, as we do not know where on the terminal
+// the region lies.
+func (t *tScreen) goTo(x, y int) {
+	if t.inline == 0 {
+		t.sendMotion(x, y)
+		return
+	}
+	if y < t.irow {
+		t.moveUp(t.irow - y)
+	} else if y > t.irow {
+		t.moveDown(y - t.irow)
+	}
+	t.irow = y
+	t.writeString("\r")
+	if x > 0 {
+		t.TPuts(t.tparm(t.cuf, x))
+	}
+}
+
+func (t *tScreen) moveUp(n int) {
+	if n <= 0 {
+		return
+	}
+	if t.cuu != "" {
+		t.TPuts(t.tparm(t.cuu, n))
+		return
+	}
+	for i := 0; i < n; i++ {
+		t.TPuts(t.ti.CursorUp1)
+	}
+}
+
+func (t *tScreen) moveDown(n int) {
+	if n <= 0 {
+		return
+	}
+	if t.cud != "" {
+		t.TPuts(t.tparm(t.cud, n))
+		return
+	}
+	// Line feed (output processing is disabled, so this does not
+	// return the carriage) never scrolls, as the region is reserved.
+	t.writeString(strings.Repeat("\n", n))
+}
+
+func (t *tScreen) Println(text string) {
+	t.Lock()
+	defer t.Unlock()
+	if t.fini || t.suspended || t.inline == 0 {
+		return
+	}
+
+	t.buf.Reset()
+	t.buffering = true
+	t.hideCursor()
+	t.goTo(0, 0)
+	t.TPuts(t.ti.AttrOff)
+	t.curstyle = styleInvalid
+	t.TPuts(t.ed)
+	policy := t.cells.WidthPolicy()
+	for _, line := range strings.Split(text, "\n") {
+		var buf []byte
+		col := 0
+		for _, r := range line {
+			switch {
+			case r == '\t':
+				n := tabStop - col%tabStop
+				buf = append(buf, strings.Repeat(" ", n)...)
+				col += n
+			case isControl(r):
+				// Other control characters could disturb the terminal.
+			default:
+				buf = append(buf, t.encodeRune(r, nil)...)
+				col += policy.RuneWidth(r)
+			}
+		}
+		buf = append(buf, '\r', '\n')
+		t.writeString(string(buf))
+	}
+	t.reserve()
+	t.buffering = false
+	t.buf.WriteTo(t.tty)
+
+	t.cells.Invalidate()
+	if !t.frame.deferDraw(frameShow) {
+		t.draw()
+	}
+}
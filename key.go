@@ -42,12 +42,21 @@ import (
 // activity than graphical applications.  Hence, they should avoid depending
 // overly much on availability of modifiers, or the availability of any
 // specific keys.
+//
+// Terminals that support the kitty keyboard protocol can do better, if
+// the application asks for it with Screen.EnableKittyKeyboard.  Then all
+// modifiers are reported unambiguously (so that Ctrl-I is reported as
+// KeyTab with ModCtrl, distinct from a plain Tab), and optionally key
+// repeat and release events, and alternate keys, are reported too.
 type EventKey struct {
-	t   time.Time
-	mod ModMask
-	key Key
-	esc string
-	ch  rune
+	t       time.Time
+	mod     ModMask
+	key     Key
+	esc     string
+	ch      rune
+	etype   KeyEventType
+	shifted rune
+	base    rune
 }
 
 // When returns the time when this Event was created, which should closely
@@ -71,6 +80,29 @@ func (ev *EventKey) Key() Key {
 	return ev.key
 }
 
+// EventType returns whether this event is for a key press, repeat, or
+// release.  Only press events are reported unless the terminal supports
+// the kitty keyboard protocol, and KeyboardReportEvents was requested.
+func (ev *EventKey) EventType() KeyEventType {
+	return ev.etype
+}
+
+// ShiftedRune returns the rune the key produces when shifted, and
+// BaseRune returns the rune for the same physical key on a standard
+// PC-101 (US) layout.  These are zero if they are not known, which is
+// the case unless KeyboardReportAlternates was requested from a terminal
+// that supports the kitty keyboard protocol.  (They are also zero if
+// they would be the same as the key's own rune.)
+func (ev *EventKey) ShiftedRune() rune {
+	return ev.shifted
+}
+
+// BaseRune returns the rune for the key on a standard layout.  See
+// ShiftedRune.
+func (ev *EventKey) BaseRune() rune {
+	return ev.base
+}
+
 // Modifiers returns the modifiers that were present with the key press.  Note
 // that not all platforms and terminals support this equally well, and some
 // cases we will not not know for sure.  Hence, applications should avoid
@@ -219,6 +251,12 @@ func (ev *EventKey) Name() string {
 	if ev.mod&ModCtrl != 0 {
 		m = append(m, "Ctrl")
 	}
+	if ev.mod&ModSuper != 0 {
+		m = append(m, "Super")
+	}
+	if ev.mod&ModHyper != 0 {
+		m = append(m, "Hyper")
+	}
 
 	ok := false
 	if s, ok = KeyNames[ev.key]; !ok {
@@ -272,14 +310,52 @@ type ModMask int16
 // with Meta, and the lack of support for it on many/most platforms, the
 // current implementations never use it.  Instead, they use ModAlt, even for
 // events that could possibly have been distinguished from ModAlt.
+// (The exception is the kitty keyboard protocol, which reports Meta,
+// Super and Hyper separately.)
 const (
 	ModShift ModMask = 1 << iota
 	ModCtrl
 	ModAlt
 	ModMeta
+	ModSuper
+	ModHyper
 	ModNone ModMask = 0
 )
 
+// KeyEventType indicates whether a key event is for a key press, an
+// automatic repeat (the key being held down), or a release.
+type KeyEventType int
+
+const (
+	KeyEventPress KeyEventType = iota
+	KeyEventRepeat
+	KeyEventRelease
+)
+
+// KeyboardFlags select the enhancements requested from terminals that
+// support the kitty keyboard protocol.
+type KeyboardFlags int
+
+const (
+	// KeyboardDisambiguate reports keys that are ambiguous in the
+	// legacy encoding (such as Ctrl-I and Tab, or Alt-[ and the start
+	// of an escape sequence) unambiguously, with all modifiers.
+	KeyboardDisambiguate KeyboardFlags = 1 << iota
+
+	// KeyboardReportEvents reports key repeat and release events,
+	// in addition to key presses.
+	KeyboardReportEvents
+
+	// KeyboardReportAlternates reports the shifted and base layout
+	// runes for keys.  See EventKey.ShiftedRune.
+	KeyboardReportAlternates
+
+	// KeyboardReportAllKeys reports all keys, including those for
+	// plain text, as escape sequences.  This is needed to get release
+	// events for text keys.
+	KeyboardReportAllKeys
+)
+
 // Key is a generic value for representing keys, and especially special
 // keys (function keys, cursor movement keys, etc.)  For normal keys, like
 // ASCII letters, we use KeyRune, and then expect the application to
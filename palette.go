@@ -0,0 +1,144 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Palette colors (OSC 4).  Querying a color is done by giving ? as its
+// value, and the terminal replies with OSC 4 ; index ; rgb:R/G/B ST.
+// Several colors may be queried, or set, with a single sequence.  OSC 104
+// restores colors (all of them, if no index is given) to their defaults.
+const (
+	paletteSet   = "\x1b]4;%d;rgb:%02x/%02x/%02x\x1b\\"
+	paletteQuery = ";%d;?"
+	paletteReset = "\x1b]104;%d\x1b\\"
+	paletteReAll = "\x1b]104\x1b\\"
+
+	oscPalette = 4
+)
+
+// resetColorMap resets the cache of colors mapped onto the palette, so
+// that the palette colors map only to themselves.
+func (t *tScreen) resetColorMap() {
+	t.colors = make(map[Color]Color)
+	for _, c := range t.palette {
+		t.colors[c] = c
+	}
+}
+
+// mapColor returns the palette color closest to c, using the actual
+// values of the palette colors if we know them.
+func (t *tScreen) mapColor(c Color) Color {
+	if v, ok := t.colors[c]; ok {
+		return v
+	}
+	v := ColorDefault
+	if i := findColorIndex(c, t.palvals); i >= 0 {
+		v = t.palette[i]
+	}
+	t.colors[c] = v
+	return v
+}
+
+func (t *tScreen) QueryPalette() ([]Color, error) {
+	if !t.isAnsi() || len(t.palette) == 0 || len(t.palette) > 256 {
+		return nil, ErrNotSupported
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("\x1b]4")
+	for i := range t.palette {
+		fmt.Fprintf(sb, paletteQuery, i)
+	}
+	sb.WriteString("\x1b\\")
+
+	t.querymu.Lock()
+	defer t.querymu.Unlock()
+
+	// Colors that the terminal does not answer for keep their defaults,
+	// rather than what an earlier query found.
+	t.Lock()
+	t.palreplies = 0
+	for i := range t.palvals {
+		if c, ok := t.palset[i]; ok {
+			t.palvals[i] = c
+		} else {
+			t.palvals[i] = t.palette[i].TrueColor()
+		}
+	}
+	t.Unlock()
+
+	t.query(queryTimeout, sb.String())
+
+	t.Lock()
+	defer t.Unlock()
+	if t.palreplies == 0 {
+		return nil, ErrNotSupported
+	}
+	t.resetColorMap()
+	return append([]Color(nil), t.palvals...), nil
+}
+
+func (t *tScreen) SetPaletteColor(index int, c Color) {
+	t.Lock()
+	defer t.Unlock()
+	if index < 0 || index >= len(t.palette) || index > 255 || !t.isAnsi() {
+		return
+	}
+	if t.palset == nil {
+		t.palset = make(map[int]Color)
+	}
+	if c.Valid() {
+		c = c.TrueColor()
+		t.palset[index] = c
+		t.palvals[index] = c
+	} else {
+		delete(t.palset, index)
+		t.palvals[index] = t.palette[index].TrueColor()
+	}
+	t.resetColorMap()
+	if !t.fini && !t.suspended {
+		t.sendPaletteColor(index)
+	}
+}
+
+// sendPaletteColor sets the terminal's palette color to the value we
+// have for it, or resets it if we have not changed it.
+func (t *tScreen) sendPaletteColor(index int) {
+	if c, ok := t.palset[index]; ok {
+		r, g, b := c.RGB()
+		t.writeString(fmt.Sprintf(paletteSet, index, r, g, b))
+	} else {
+		t.writeString(fmt.Sprintf(paletteReset, index))
+	}
+}
+
+// sendPalette sets the palette colors we have changed, when the terminal
+// is engaged.
+func (t *tScreen) sendPalette() {
+	for index := range t.palset {
+		t.sendPaletteColor(index)
+	}
+}
+
+// resetPalette restores the terminal's palette, if we changed it.
+func (t *tScreen) resetPalette() {
+	if len(t.palset) > 0 {
+		t.writeString(paletteReAll)
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Queries sent to probe the terminal.  Every terminal we know of answers
+// the Primary Device Attributes query, and terminals answer queries in
+// the order they are sent, so it is sent last: its reply tells us that
+// we have heard all that we are going to.
+const (
+	probePrimary   = "\x1b[c"
+	probeSecondary = "\x1b[>c"
+	probeVersion   = "\x1b[>0q"
+	probeMode      = "\x1b[?%d$p"
+	probeKittyKeys = "\x1b[?u"
+
+	probeTimeout = 100 * time.Millisecond
+	queryTimeout = 500 * time.Millisecond
+)
+
+// Queries for the default foreground and background colors (OSC 10 and
+// OSC 11).  The replies have the same form, with the color in place of
+// the question mark, as rgb:RRRR/GGGG/BBBB.
+const (
+	queryFgColor = "\x1b]10;?\x1b\\"
+	queryBgColor = "\x1b]11;?\x1b\\"
+
+	oscFgColor = 10
+	oscBgColor = 11
+)
+
+// Private modes that we ask about with DECRQM.
+const (
+	modeFocus      = 1004
+	modePaste      = 2004
+	modeSyncOutput = 2026
+)
+
+// probe is called by Init.  The terminal is only probed if the
+// application asks for it, with Probe, or TCELL_PROBE=enable is set in the
+// environment.  Otherwise nothing is asked, except the cell size when
+// sixel images are already in use; that reply is handled whenever it
+// arrives with other input.
+func (t *tScreen) probe() {
+	if t.isAnsi() && os.Getenv("TCELL_PROBE") == "enable" {
+		t.Probe()
+		return
+	}
+	if t.imgproto == imageSixel {
+		t.TPuts(cellSizeQuery)
+	}
+}
+
+// Probe asks the terminal about itself, and waits a bounded time for the
+// replies, which are consumed as they arrive with other input.  Setting
+// TCELL_PROBE=disable in the environment prevents it.
+func (t *tScreen) Probe() error {
+	if !t.isAnsi() || os.Getenv("TCELL_PROBE") == "disable" {
+		return ErrNotSupported
+	}
+
+	queries := []string{
+		probeVersion,
+		probeSecondary,
+		fmt.Sprintf(probeMode, modeFocus),
+		fmt.Sprintf(probeMode, modePaste),
+	}
+	if !t.syncfixed {
+		queries = append(queries, fmt.Sprintf(probeMode, modeSyncOutput))
+	}
+	queries = append(queries, probeKittyKeys, cellSizeQuery)
+	t.querymu.Lock()
+	defer t.querymu.Unlock()
+	if !t.query(probeTimeout, queries...) {
+		return ErrNotSupported
+	}
+	return nil
+}
+
+// query sends the queries to the terminal, followed by a request for the
+// Primary Device Attributes, and then waits until the terminal answers
+// that (and so has answered the others, if it is going to), or until
+// the timeout expires.  It returns false if it timed out.  The replies
+// are handled as they arrive with other input, so the caller must not
+// hold the lock.  It must hold querymu, from before it resets whatever
+// the replies set until it has read them, so that other queries do not
+// interfere.
+func (t *tScreen) query(timeout time.Duration, queries ...string) bool {
+	t.Lock()
+	if t.fini || t.suspended {
+		t.Unlock()
+		return false
+	}
+	q := make(chan struct{})
+	t.probeq = q
+	for _, s := range queries {
+		t.TPuts(s)
+	}
+	t.TPuts(probePrimary)
+	t.Unlock()
+
+	answered := true
+	select {
+	case <-q:
+	case <-time.After(timeout):
+		answered = false
+	}
+
+	t.Lock()
+	t.probeq = nil
+	t.Unlock()
+	return answered
+}
+
+func (t *tScreen) QueryDefaultColors() (Color, Color, error) {
+	if !t.isAnsi() {
+		return ColorDefault, ColorDefault, ErrNotSupported
+	}
+	t.querymu.Lock()
+	defer t.querymu.Unlock()
+
+	t.Lock()
+	t.deffg = ColorDefault
+	t.defbg = ColorDefault
+	t.Unlock()
+
+	t.query(queryTimeout, queryFgColor, queryBgColor)
+
+	t.Lock()
+	defer t.Unlock()
+	if t.deffg == ColorDefault || t.defbg == ColorDefault {
+		return ColorDefault, ColorDefault, ErrNotSupported
+	}
+	return t.deffg, t.defbg, nil
+}
+
+// probeDone is called when the terminal has answered the probe, to make
+// use of what it told us.
+func (t *tScreen) probeDone() {
+	t.caps.Probed = true
+	if !t.imgfixed && t.imgproto == imageNone {
+		v := t.caps.Version
+		switch {
+		case strings.HasPrefix(v, "kitty"),
+			strings.HasPrefix(v, "WezTerm"),
+			strings.HasPrefix(v, "ghostty"):
+			t.imgproto = imageKitty
+		case t.caps.Sixel:
+			t.imgproto = imageSixel
+		}
+	}
+	if t.probeq != nil {
+		close(t.probeq)
+		t.probeq = nil
+	}
+}
+
+func (t *tScreen) Capabilities() Capabilities {
+	t.Lock()
+	defer t.Unlock()
+	caps := t.caps
+	caps.Attributes = append([]int(nil), t.caps.Attributes...)
+	return caps
+}
+
+// parseDeviceReport is like parseSgrMouse, but it parses the replies to
+// the queries we use to probe the terminal: Primary and Secondary Device
+// Attributes (CSI ? params c, and CSI > params c), and DECRPM reports
+// (CSI ? mode ; value $ y).  These are consumed, and never delivered to
+// the application.
+func (t *tScreen) parseDeviceReport(buf *bytes.Buffer) (bool, bool) {
+	b := buf.Bytes()
+
+	var p [16]int
+	n := 0
+	var lead byte
+	state := 0
+	if t.escaped {
+		state = 1
+	}
+
+	for i := range b {
+		switch state {
+		case 0:
+			switch b[i] {
+			case '\x1b':
+				state = 1
+			case '\x9b':
+				state = 2
+			default:
+				return false, false
+			}
+		case 1:
+			if b[i] != '[' {
+				return false, false
+			}
+			state = 2
+		case 2:
+			if b[i] != '?' && b[i] != '>' {
+				return false, false
+			}
+			lead = b[i]
+			state = 3
+		case 3:
+			switch c := b[i]; {
+			case c >= '0' && c <= '9':
+				if n < len(p) {
+					p[n] = p[n]*10 + int(c-'0')
+				}
+			case c == ';':
+				// Parameters beyond the last that we keep are ignored.
+				if n < len(p) {
+					n++
+				}
+			case c == '$' && lead == '?' && n == 1:
+				state = 4
+			case c == 'c':
+				buf.Next(i + 1)
+				t.escbuf.Reset()
+				t.escaped = false
+				if n < len(p) {
+					n++
+				}
+				if lead == '?' {
+					t.caps.Class = p[0]
+					t.caps.Attributes = append([]int(nil), p[1:n]...)
+					for _, a := range t.caps.Attributes {
+						if a == 4 {
+							t.caps.Sixel = true
+						}
+					}
+					t.probeDone()
+				} else {
+					t.caps.TerminalID = p[0]
+					t.caps.Firmware = p[1]
+				}
+				return true, true
+			default:
+				return false, false
+			}
+		case 4:
+			if b[i] != 'y' {
+				return false, false
+			}
+			buf.Next(i + 1)
+			t.escbuf.Reset()
+			t.escaped = false
+			t.modeReport(p[0], p[1])
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// modeReport records the terminal's support for a private mode.  The
+// value is 0 if the mode is not recognized, 1 or 2 if it is set or
+// reset, and 3 or 4 if it is permanently set or reset.
+func (t *tScreen) modeReport(mode, val int) {
+	known := val == 1 || val == 2
+	switch mode {
+	case modeSyncOutput:
+		t.caps.SyncOutput = known
+		if !t.syncfixed {
+			t.syncout = known || t.ti.Sync != ""
+		}
+	case modePaste:
+		t.caps.BracketedPaste = known
+	case modeFocus:
+		t.caps.FocusEvents = known
+	}
+}
+
+// parseVersion is like parseSgrMouse, but it parses the reply to
+// XTVERSION, which is DCS > | text ST.
+func (t *tScreen) parseVersion(buf *bytes.Buffer) (bool, bool) {
+	b := buf.Bytes()
+
+	state := 0
+	start := 0
+	if t.escaped {
+		state = 1
+	}
+
+	for i := range b {
+		switch state {
+		case 0:
+			switch b[i] {
+			case '\x1b':
+				state = 1
+			case '\x90':
+				state = 2
+			default:
+				return false, false
+			}
+		case 1:
+			if b[i] != 'P' {
+				return false, false
+			}
+			state = 2
+		case 2:
+			if b[i] != '>' {
+				return false, false
+			}
+			state = 3
+		case 3:
+			if b[i] != '|' {
+				return false, false
+			}
+			start = i + 1
+			state = 4
+		case 4:
+			if b[i] == '\x1b' {
+				state = 5
+			}
+		case 5:
+			if b[i] != '\\' {
+				return false, false
+			}
+			t.caps.Version = string(b[start : i-1])
+			buf.Next(i + 1)
+			t.escbuf.Reset()
+			t.escaped = false
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// parseColorReport is like parseSgrMouse, but it parses the terminal's
+// report of a color, OSC Ps ; rgb:RRRR/GGGG/BBBB ST (where ST may also
+// be BEL), in reply to a query of the default foreground or background
+// color, or OSC 4 ; index ; rgb:RRRR/GGGG/BBBB ST for a palette color.
+func (t *tScreen) parseColorReport(buf *bytes.Buffer) (bool, bool) {
+	b := buf.Bytes()
+
+	state := 0
+	ps := 0
+	index := 0
+	start := 0
+	if t.escaped {
+		state = 1
+	}
+
+	for i := range b {
+		switch state {
+		case 0:
+			switch b[i] {
+			case '\x1b':
+				state = 1
+			case '\x9d':
+				state = 2
+			default:
+				return false, false
+			}
+		case 1:
+			if b[i] != ']' {
+				return false, false
+			}
+			state = 2
+		case 2:
+			switch {
+			case b[i] >= '0' && b[i] <= '9':
+				ps = ps*10 + int(b[i]-'0')
+			case b[i] == ';' && (ps == oscFgColor || ps == oscBgColor):
+				start = i + 1
+				state = 3
+			case b[i] == ';' && ps == oscPalette:
+				state = 5
+			default:
+				return false, false
+			}
+		case 5:
+			switch {
+			case b[i] >= '0' && b[i] <= '9' && index < 256:
+				index = index*10 + int(b[i]-'0')
+			case b[i] == ';' && b[i-1] != ';':
+				start = i + 1
+				state = 3
+			default:
+				return false, false
+			}
+		case 3, 4:
+			end := i
+			switch {
+			case state == 4:
+				if b[i] != '\\' {
+					return false, false
+				}
+				end = i - 1
+			case b[i] == '\x1b':
+				state = 4
+				continue
+			case b[i] != '\a' && b[i] != '\x9c':
+				if i-start > len("rgb:ffff/ffff/ffff") {
+					return false, false
+				}
+				continue
+			}
+			buf.Next(i + 1)
+			t.escbuf.Reset()
+			t.escaped = false
+			c := parseXColor(string(b[start:end]))
+			switch ps {
+			case oscFgColor:
+				t.deffg = c
+			case oscBgColor:
+				t.defbg = c
+			case oscPalette:
+				if c != ColorDefault && index < len(t.palvals) {
+					t.palvals[index] = c
+					t.palreplies++
+				}
+			}
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// parseXColor parses a color in the form used by X11 (and terminals),
+// rgb:R/G/B, where each component has from one to four hex digits.
+// ColorDefault is returned if it is not in that form.
+func parseXColor(s string) Color {
+	if !strings.HasPrefix(s, "rgb:") {
+		return ColorDefault
+	}
+	parts := strings.Split(s[len("rgb:"):], "/")
+	if len(parts) != 3 {
+		return ColorDefault
+	}
+	var rgb [3]int32
+	for i, p := range parts {
+		v, e := strconv.ParseUint(p, 16, 16)
+		if e != nil || len(p) == 0 || len(p) > 4 {
+			return ColorDefault
+		}
+		max := uint64(1)<<(4*uint(len(p))) - 1
+		rgb[i] = int32(v * 255 / max)
+	}
+	return NewRGBColor(rgb[0], rgb[1], rgb[2])
+}
@@ -14,6 +14,10 @@
 
 package tcell
 
+import (
+	"image"
+)
+
 // Screen represents the physical (or emulated) screen.
 // This can be a terminal window or a physical console.  Platforms implement
 // this differerently.
@@ -32,6 +36,15 @@ type Screen interface {
 	// Fill fills the screen with the given character and style.
 	Fill(rune, Style)
 
+	// ScrollRegion moves the contents of the lines from top to bottom
+	// (inclusive) up by n lines, or down if n is negative.  Lines moved
+	// out of the region are lost, and the lines exposed are filled with
+	// spaces using the global default style.  When the screen is next
+	// shown, the terminal is asked to do the scrolling itself if it can,
+	// so that only the exposed lines need to be drawn, which is much
+	// faster than redrawing the whole region.
+	ScrollRegion(top, bottom, n int)
+
 	// SetCell is an older API, and will be removed.  Please use
 	// SetContent instead; SetCell is implemented in terms of SetContent.
 	SetCell(x int, y int, style Style, ch ...rune)
@@ -60,6 +73,41 @@ type Screen interface {
 	// last column will be replaced with a single width space on output.
 	SetContent(x int, y int, mainc rune, combc []rune, style Style)
 
+	// SetContentString is like SetContent, but takes the contents as a
+	// string holding a single grapheme cluster, which is what the user
+	// sees as a single character.  This may be made of several runes,
+	// for example an emoji with a skin tone modifier, a flag, or emoji
+	// joined into a sequence with ZWJ.  The width of the cell is that of
+	// the cluster as a whole.  Only the first cluster of the string is
+	// used.
+	SetContentString(x int, y int, cluster string, style Style)
+
+	// SetContents sets the contents of a run of cells, starting at the
+	// given location and continuing to the right, with cells[i] placed
+	// in column x+i, just as SetContent would place it.  Cells past the
+	// end of the line are ignored.  This is much faster than calling
+	// SetContent for each cell.
+	SetContents(x int, y int, cells []Cell)
+
+	// PutString sets the contents of cells, starting at the given
+	// location and continuing to the right, to the grapheme clusters of
+	// the string, in the given style.  Each cluster occupies one cell,
+	// or two if it is wide.  Clusters that do not fit on the line are
+	// ignored.  It returns the number of cells used.  This is much
+	// faster than calling SetContent for each cell.
+	PutString(x int, y int, str string, style Style) int
+
+	// SetWidthPolicy sets the policy used to determine how many cells
+	// characters occupy, which should match what the terminal does.
+	// The whole screen is redrawn by the next Show.  The default is
+	// DefaultWidthPolicy().
+	SetWidthPolicy(policy WidthPolicy)
+
+	// WidthPolicy returns the policy used to determine how many cells
+	// characters occupy.  Applications measuring text for layout should
+	// use this policy to do so.
+	WidthPolicy() WidthPolicy
+
 	// SetStyle sets the default style to use when clearing the screen
 	// or when StyleDefault is specified.  If it is also StyleDefault,
 	// then whatever system/terminal default is relevant will be used.
@@ -104,6 +152,33 @@ type Screen interface {
 	// DisableMouse disables the mouse.
 	DisableMouse()
 
+	// EnableFocus enables reporting of focus changes, which are
+	// delivered as EventFocus.  (If your terminal supports it.)
+	EnableFocus()
+
+	// DisableFocus disables reporting of focus changes.
+	DisableFocus()
+
+	// EnableKittyKeyboard asks the terminal to report keys using the
+	// kitty keyboard protocol, with the given enhancements.  Terminals
+	// that do not support the protocol ignore this, and keep using the
+	// legacy encoding.  This is not enabled by default.
+	EnableKittyKeyboard(KeyboardFlags)
+
+	// DisableKittyKeyboard restores the legacy keyboard encoding.
+	DisableKittyKeyboard()
+
+	// EnableModifyOtherKeys asks the terminal to report modified keys
+	// using xterm's modifyOtherKeys (at level 2).  This is supported by
+	// xterm and tmux (among others), and allows keys such as Ctrl-Enter,
+	// Ctrl-Shift-A and Ctrl-1 to be told apart from their unmodified
+	// counterparts.  This is not enabled by default.
+	EnableModifyOtherKeys()
+
+	// DisableModifyOtherKeys restores the terminal's default reporting
+	// of modified keys.
+	DisableModifyOtherKeys()
+
 	// HasMouse returns true if the terminal (apparently) supports a
 	// mouse.  Note that the a return value of true doesn't guarantee that
 	// a mouse/pointing device is present; a false return definitely
@@ -132,6 +207,39 @@ type Screen interface {
 	// or during a resize event.
 	Sync()
 
+	// BeginFrame begins a group of changes that are to be displayed
+	// together.  Until the frame ends, nothing is drawn: calls to Show()
+	// and Sync(), from any goroutine, and the redraw that follows a
+	// resize, are deferred until then, so that the display never shows
+	// some of the changes without the rest.  Frames may be begun by more
+	// than one goroutine at once, and drawing waits for all of them.
+	// Every call must be matched by a call to EndFrame().
+	BeginFrame()
+
+	// EndFrame ends a frame begun with BeginFrame().  When the last frame
+	// ends, any drawing deferred during it is done.  Changes are not
+	// otherwise displayed; call Show() to do that.
+	EndFrame()
+
+	// SetMaxFPS limits the rate at which the screen is drawn to fps frames
+	// per second.  Show() then only marks the screen to be drawn, which is
+	// done in the background, no sooner than one frame interval after the
+	// last, and changes made in the meantime are drawn together.  This
+	// keeps applications that call Show() after every event (as during a
+	// paste, or dragging the mouse) from sending more to the terminal than
+	// it can keep up with.  ShowNow() and Sync() still draw immediately.
+	// Zero (the default) draws on every call to Show().  Only screens
+	// drawn to a terminal through terminfo limit the rate; others ignore
+	// it.
+	SetMaxFPS(fps int)
+
+	// ShowNow works like Show(), but it draws immediately even when the
+	// frame rate is limited with SetMaxFPS(), for changes that should not
+	// wait (such as echoing a key).  Like Show(), it only draws the cells
+	// that changed, and it waits for any frame begun with BeginFrame() to
+	// end.
+	ShowNow()
+
 	// CharacterSet returns information about the character set.
 	// This isn't the full locale, but it does give us the input/output
 	// character set.  Note that this is just for diagnostic purposes,
@@ -217,8 +325,111 @@ type Screen interface {
 	// Beep attempts to sound an OS-dependent audible alert and returns an error
 	// when unsuccessful.
 	Beep() error
+
+	// Suspend pauses input and output processing, and returns the
+	// terminal to the state it was in before Init was called.  This
+	// allows a subprocess (such as a shell or an editor) to take over
+	// the terminal, or the application to stop itself for job control.
+	// Input that has already been read is retained, and will be
+	// delivered after Resume is called.  No drawing is performed while
+	// the screen is suspended.  If reading from the terminal cannot be
+	// stopped promptly, ErrInputNotStopped is returned.
+	Suspend() error
+
+	// Resume undoes the effects of Suspend, taking control of the
+	// terminal again.  The screen size is checked, and the entire
+	// screen is redrawn as if Sync had been called.
+	Resume() error
+
+	// SetTitle sets the title of the window containing the screen, if
+	// the terminal supports that.  Where possible, the title that was in
+	// effect before is restored when the screen is finalized (or while
+	// it is suspended).
+	SetTitle(title string)
+
+	// SetIconName sets the name shown for the window when it is
+	// iconified.  This is not supported everywhere, and has no effect
+	// on terminals that do not distinguish it from the title.
+	SetIconName(name string)
+
+	// SetCursorStyle sets the shape of the cursor, and whether it
+	// blinks.  CursorStyleDefault selects whatever the terminal uses
+	// by default, which is also restored when the screen is finalized.
+	// Terminals that cannot change the cursor ignore this.
+	SetCursorStyle(CursorStyle)
+
+	// SetCursorColor sets the color of the cursor.  ColorDefault
+	// selects the terminal's own cursor color, which is also restored
+	// when the screen is finalized.  Not all terminals support this.
+	SetCursorColor(Color)
+
+	// ShowImage displays an image, scaled to fill the rectangle of cells
+	// of the given width and height with its top left corner at x, y.
+	// The image appears on the next Show, and the cells beneath it are
+	// not drawn while it is there.  Images are removed by ClearImages,
+	// Clear and Sync.  The kitty graphics protocol or sixel graphics
+	// are used, depending on the terminal; if it supports neither,
+	// ErrNotSupported is returned.  Images may not start off the top or
+	// left of the screen; ErrOffScreen is returned if x or y is negative.
+	ShowImage(x, y, w, h int, img image.Image) error
+
+	// ClearImages removes all images, revealing the cells beneath them
+	// on the next Show.
+	ClearImages()
+
+	// Probe asks the terminal about itself, waiting a short time for it
+	// to answer.  What it says is then available from Capabilities(), and
+	// is used to choose features such as synchronized output and image
+	// support.  Probe returns ErrNotSupported if the terminal does not
+	// answer, or cannot be asked.  The terminal is not probed otherwise,
+	// unless TCELL_PROBE=enable is set in the environment, in which case
+	// it is probed by Init.  Setting TCELL_PROBE=disable prevents probing.
+	Probe() error
+
+	// Capabilities returns what the terminal has reported about itself
+	// when it was probed.
+	Capabilities() Capabilities
+
+	// QueryDefaultColors asks the terminal for its default foreground
+	// and background colors (those used for ColorDefault), returning
+	// them as RGB colors.  This can be used, for example, to choose
+	// between light and dark color schemes.  It waits a short time for
+	// the terminal to answer, and returns ErrNotSupported if it does
+	// not.
+	QueryDefaultColors() (fg Color, bg Color, err error)
+
+	// QueryPalette asks the terminal for the actual values of its palette
+	// colors (the first Colors() of them, up to 256), returning them as
+	// RGB colors indexed by palette number.  These values are then used
+	// when mapping RGB colors onto the palette, which gives a much better
+	// result when the palette differs from the standard one.  Colors that
+	// the terminal does not report have their default values.  It waits a
+	// short time for the terminal to answer, and returns ErrNotSupported
+	// if it does not.
+	QueryPalette() ([]Color, error)
+
+	// SetPaletteColor changes the palette color with the given index to
+	// the given color.  Passing ColorDefault restores the color to the
+	// terminal's default.  Any colors changed are restored when the
+	// screen is finalized (or suspended).  Terminals that do not support
+	// this ignore it.
+	SetPaletteColor(index int, c Color)
 }
 
+// CursorStyle represents the shape of the cursor, and whether it blinks.
+// The values are those used by the DECSCUSR escape sequence.
+type CursorStyle int
+
+const (
+	CursorStyleDefault = CursorStyle(iota)
+	CursorStyleBlinkingBlock
+	CursorStyleSteadyBlock
+	CursorStyleBlinkingUnderline
+	CursorStyleSteadyUnderline
+	CursorStyleBlinkingBar
+	CursorStyleSteadyBar
+)
+
 // NewScreen returns a default Screen suitable for the user's terminal
 // environment.
 func NewScreen() (Screen, error) {
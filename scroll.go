@@ -0,0 +1,114 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+// Standard (ECMA-48) sequences for scrolling, used when terminfo lacks
+// them.  We use IND rather than a newline to scroll forward, as the tty
+// might translate the newline.
+const (
+	scrollRegionSet = "\x1b[%i%p1%d;%p2%dr"
+	scrollFwd       = "\x1bD"
+	scrollRev       = "\x1bM"
+	insertLines     = "\x1b[%p1%dL"
+	deleteLines     = "\x1b[%p1%dM"
+)
+
+// tScroll is a scroll of part of the screen, waiting to be sent to the
+// terminal.
+type tScroll struct {
+	top, bottom int
+	n           int
+}
+
+func (t *tScreen) ScrollRegion(top, bottom, n int) {
+	t.Lock()
+	defer t.Unlock()
+
+	if top < 0 {
+		top = 0
+	}
+	if bottom >= t.h {
+		bottom = t.h - 1
+	}
+	if t.fini || top > bottom || n == 0 {
+		return
+	}
+	t.cells.ScrollRegion(top, bottom, n, t.style)
+	if n < bottom-top+1 && -n < bottom-top+1 && t.canScroll() {
+		t.scrolls = append(t.scrolls, tScroll{top: top, bottom: bottom, n: n})
+		return
+	}
+	// The terminal cannot do it for us, so just redraw the region.
+	for y := top; y <= bottom; y++ {
+		for x := 0; x < t.w; x++ {
+			t.cells.SetDirty(x, y, true)
+		}
+	}
+}
+
+// canScroll returns true if we can have the terminal scroll part of the
+// screen.  Images would be moved (or not) unpredictably, so we do not
+// scroll when any are shown.
+func (t *tScreen) canScroll() bool {
+	if t.suspended || t.inline > 0 || len(t.images) > 0 {
+		return false
+	}
+	return (t.csr != "" && t.ind != "" && t.ri != "") ||
+		(t.il != "" && t.dl != "")
+}
+
+// sendScrolls has the terminal scroll the screen as the application
+// asked, before the exposed lines are drawn.  A scroll region is used if
+// possible, otherwise lines are deleted and inserted.
+func (t *tScreen) sendScrolls() {
+	for _, s := range t.scrolls {
+		n := s.n
+		if n < 0 {
+			n = -n
+		}
+		if t.csr != "" && t.ind != "" && t.ri != "" {
+			t.TPuts(t.tparm(t.csr, s.top, s.bottom))
+			if s.n > 0 {
+				t.goTo(0, s.bottom)
+				for i := 0; i < n; i++ {
+					t.TPuts(t.ind)
+				}
+			} else {
+				t.goTo(0, s.top)
+				for i := 0; i < n; i++ {
+					t.TPuts(t.ri)
+				}
+			}
+			t.TPuts(t.tparm(t.csr, 0, t.h-1))
+		} else if s.n > 0 {
+			t.goTo(0, s.top)
+			t.TPuts(t.tparm(t.dl, n))
+			if s.bottom < t.h-1 {
+				t.goTo(0, s.bottom-n+1)
+				t.TPuts(t.tparm(t.il, n))
+			}
+		} else {
+			if s.bottom < t.h-1 {
+				t.goTo(0, s.bottom-n+1)
+				t.TPuts(t.tparm(t.dl, n))
+			}
+			t.goTo(0, s.top)
+			t.TPuts(t.tparm(t.il, n))
+		}
+		t.cx = -1
+		t.cy = -1
+	}
+	t.scrolls = nil
+}
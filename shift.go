@@ -0,0 +1,133 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+// Standard (ECMA-48) sequences to insert and delete characters, used
+// when terminfo lacks them.
+const (
+	insertChars = "\x1b[%p1%d@"
+	deleteChars = "\x1b[%p1%dP"
+)
+
+// maxShift is the furthest that we look for text that has moved along
+// a line.  Edits are usually much smaller than this.
+const maxShift = 32
+
+// shiftRow looks for text in row y that has moved along the line, as it
+// does when a character is typed or deleted in the middle of it.  If it
+// is shorter, the terminal is told to insert or delete characters, to
+// move what it already shows, so that only the new characters need to be
+// drawn, rather than the rest of the line.
+func (t *tScreen) shiftRow(y int) {
+	if len(t.images) > 0 {
+		return
+	}
+	cb := &t.cells
+	x := 0
+	for x < t.w && !cb.Dirty(x, y) {
+		x++
+	}
+	if x >= t.w-1 {
+		return
+	}
+
+	// Wide characters would make the columns hard to reason about.
+	dirty := 0
+	for i := x; i < t.w; i++ {
+		if _, _, _, width := cb.GetContent(i, y); width != 1 {
+			return
+		}
+		if cb.Dirty(i, y) {
+			dirty++
+		}
+	}
+
+	// Every dirty cell takes at least a byte to draw, so the saving is
+	// at least the number of cells that no longer need to be drawn, less
+	// the cost of the shift.
+	best, shift := 0, 0
+	for n := 1; n <= maxShift && x+n < t.w; n++ {
+		if cb.lastMatches(x+n, x, y) {
+			left := n // the inserted cells
+			for i := x + n; i < t.w; i++ {
+				if !cb.lastMatches(i, i-n, y) {
+					left++
+				}
+			}
+			if cost, ok := t.insertCost(n); ok && dirty-left-cost > best {
+				best, shift = dirty-left-cost, n
+			}
+		}
+		if cb.lastMatches(x, x+n, y) {
+			left := n // the cells exposed at the end of the line
+			for i := x; i < t.w-n; i++ {
+				if !cb.lastMatches(i, i+n, y) {
+					left++
+				}
+			}
+			if cost, ok := t.deleteCost(n); ok && dirty-left-cost > best {
+				best, shift = dirty-left-cost, -n
+			}
+		}
+	}
+	if shift == 0 {
+		return
+	}
+
+	if t.cy != y || t.cx != x {
+		t.goTo(x, y)
+		t.cx = x
+		t.cy = y
+	}
+	switch {
+	case shift < 0:
+		t.TPuts(t.tparm(t.dch, -shift))
+		cb.shiftLast(x, y, shift)
+	case t.ich != "":
+		t.TPuts(t.tparm(t.ich, shift))
+		cb.shiftLast(x, y, shift)
+	default:
+		// Draw the new characters in insert mode.
+		cb.shiftLast(x, y, shift)
+		t.TPuts(t.smir)
+		for i := x; i < x+shift; i++ {
+			t.drawCell(i, y)
+		}
+		t.TPuts(t.rmir)
+	}
+}
+
+// insertCost returns the number of bytes needed to insert n characters,
+// beyond those needed to draw them, and false if it cannot be done.
+func (t *tScreen) insertCost(n int) (int, bool) {
+	if t.ich != "" {
+		return len(t.tparm(t.ich, n)), true
+	}
+	if t.smir != "" && t.rmir != "" {
+		return len(t.smir) + len(t.rmir), true
+	}
+	return 0, false
+}
+
+// deleteCost returns the number of bytes needed to delete n characters,
+// and then move to the end of the line to draw the columns exposed there
+// (which is no further than the bottom right corner), and false if it
+// cannot be done.
+func (t *tScreen) deleteCost(n int) (int, bool) {
+	if t.dch == "" {
+		return 0, false
+	}
+	return len(t.tparm(t.dch, n)) + len(t.tgoto(t.w-1, t.h-1)), true
+}
@@ -15,6 +15,8 @@
 package tcell
 
 import (
+	"image"
+	"sync"
 	"testing"
 )
 
@@ -150,3 +152,258 @@ func TestBeep(t *testing.T) {
 		}
 	}
 }
+
+func TestSuspendResume(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	s.SetContent(1, 1, 'A', nil, StyleDefault)
+	s.ShowCursor(1, 1)
+	s.Show()
+
+	if err := s.Suspend(); err != nil {
+		t.Fatalf("failed to suspend: %v", err)
+	}
+	if _, _, vis := s.GetCursor(); vis {
+		t.Errorf("cursor should not be visible while suspended")
+	}
+
+	// Nothing should be drawn while suspended.
+	s.SetContent(1, 1, 'B', nil, StyleDefault)
+	s.Show()
+	b, x, _ := s.GetContents()
+	if cell := &b[1*x+1]; len(cell.Runes) != 1 || cell.Runes[0] != 'A' {
+		t.Errorf("screen updated while suspended: %v", cell)
+	}
+
+	if err := s.Resume(); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	b, x, _ = s.GetContents()
+	if cell := &b[1*x+1]; len(cell.Runes) != 1 || cell.Runes[0] != 'B' {
+		t.Errorf("screen not redrawn after resume: %v", cell)
+	}
+	if cx, cy, vis := s.GetCursor(); !vis || cx != 1 || cy != 1 {
+		t.Errorf("cursor not restored after resume (%d, %d, %v)", cx, cy, vis)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	s.SetTitle("hello.go")
+	s.SetIconName("hello")
+	if title := s.GetTitle(); title != "hello.go" {
+		t.Errorf("title should be hello.go, was %q", title)
+	}
+	if icon := s.GetIconName(); icon != "hello" {
+		t.Errorf("icon name should be hello, was %q", icon)
+	}
+}
+
+func TestCursorStyle(t *testing.T) {
+	s := mkTestScreen(t, "")
+
+	s.SetCursorStyle(CursorStyleSteadyBar)
+	s.SetCursorColor(ColorRed)
+	if cs, cc := s.GetCursorStyle(); cs != CursorStyleSteadyBar || cc != ColorRed {
+		t.Errorf("cursor style should be bar, red, was %v, %v", cs, cc)
+	}
+
+	s.Fini()
+	if cs, cc := s.GetCursorStyle(); cs != CursorStyleDefault || cc != ColorDefault {
+		t.Errorf("cursor style not restored, was %v, %v", cs, cc)
+	}
+}
+
+func TestImages(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	if e := s.ShowImage(1, 2, 3, 4, img); e != nil {
+		t.Fatalf("Failed to show image: %v", e)
+	}
+	images := s.GetImages()
+	if len(images) != 1 || images[0] != (SimImage{1, 2, 3, 4, img}) {
+		t.Errorf("Bad images: %v", images)
+	}
+	s.Clear()
+	if images = s.GetImages(); len(images) != 0 {
+		t.Errorf("Images not cleared: %v", images)
+	}
+	if e := s.ShowImage(0, -1, 3, 4, img); e != ErrOffScreen {
+		t.Errorf("Expected ErrOffScreen, got %v", e)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	s.SetPaletteColor(1, ColorGreen)
+	pal, e := s.QueryPalette()
+	if e != nil {
+		t.Fatalf("Failed to query palette: %v", e)
+	}
+	if len(pal) != 256 || pal[0] != ColorBlack.TrueColor() || pal[1] != ColorGreen.TrueColor() {
+		t.Errorf("Bad palette: %v", pal[:2])
+	}
+	s.SetPaletteColor(1, ColorDefault)
+	if pal, _ = s.QueryPalette(); pal[1] != ColorMaroon.TrueColor() {
+		t.Errorf("Palette color not reset: %x", pal[1].Hex())
+	}
+}
+
+func TestSetContentString(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	flag := "\U0001f1fa\U0001f1f8"
+	s.SetContentString(1, 1, flag+"x", StyleDefault)
+	mainc, combc, _, width := s.GetContent(1, 1)
+	if string(append([]rune{mainc}, combc...)) != flag || width != 2 {
+		t.Errorf("Bad content: %q %d", string(append([]rune{mainc}, combc...)), width)
+	}
+	s.Show()
+	b, w, _ := s.GetContents()
+	if cell := &b[1*w+1]; string(cell.Bytes) != flag {
+		t.Errorf("Bad cell bytes: %q", cell.Bytes)
+	}
+
+	// The same base rune alone is narrow again.
+	s.SetContent(1, 1, '\U0001f1fa', nil, StyleDefault)
+	if _, _, _, width = s.GetContent(1, 1); width != 1 {
+		t.Errorf("Width not updated: %d", width)
+	}
+}
+
+func TestSetWidthPolicy(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	s.SetContent(0, 0, 'α', nil, StyleDefault)
+	s.SetWidthPolicy(WidthPolicy{AmbiguousWide: true})
+	if _, _, _, w := s.GetContent(0, 0); w != 2 {
+		t.Errorf("Width not updated by policy: %d", w)
+	}
+	if p := s.WidthPolicy(); !p.AmbiguousWide {
+		t.Errorf("Policy not returned")
+	}
+	s.SetWidthPolicy(WidthPolicy{Overrides: map[rune]int{'α': 2}})
+	s.SetContent(1, 0, 'α', nil, StyleDefault)
+	if _, _, _, w := s.GetContent(1, 0); w != 2 {
+		t.Errorf("Override not used: %d", w)
+	}
+}
+
+func TestScrollRegion(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	for y := 0; y < 5; y++ {
+		s.SetContent(0, y, rune('a'+y), nil, StyleDefault)
+	}
+	s.Show()
+	s.ScrollRegion(1, 3, 1)
+	s.Show()
+	b, w, _ := s.GetContents()
+	for y, want := range "acd e" {
+		if r := b[y*w].Runes[0]; r != want {
+			t.Errorf("Line %d: expected %q, got %q", y, want, r)
+		}
+	}
+	s.ScrollRegion(0, 4, -1)
+	s.Show()
+	b, w, _ = s.GetContents()
+	for y, want := range " acd " {
+		if r := b[y*w].Runes[0]; r != want {
+			t.Errorf("Line %d: expected %q, got %q", y, want, r)
+		}
+	}
+}
+
+func TestPutString(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	style := StyleDefault.Bold(true)
+	if n := s.PutString(2, 1, "a世e\u0301!", style); n != 5 {
+		t.Errorf("Expected 5 cells used, got %d", n)
+	}
+	s.Show()
+	b, w, _ := s.GetContents()
+	for i, want := range []string{"a", "世", "e\u0301", "!"} {
+		x := []int{2, 3, 5, 6}[i]
+		if cell := &b[w+x]; string(cell.Runes) != want || cell.Style != style {
+			t.Errorf("Cell %d: expected %q, got %q", x, want, string(cell.Runes))
+		}
+	}
+
+	// Clipped at the end of the line.
+	if n := s.PutString(w-2, 2, "abc", style); n != 2 {
+		t.Errorf("Expected 2 cells used, got %d", n)
+	}
+}
+
+func TestSetContents(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	w, _ := s.Size()
+	red := StyleDefault.Foreground(ColorRed)
+	s.SetContents(w-3, 0, []Cell{
+		{Mainc: 'a', Style: red},
+		{Mainc: 'e', Combc: []rune{'\u0301'}},
+		{Mainc: 'c'},
+		{Mainc: 'd'},
+	})
+	for i, want := range []string{"a", "e\u0301", "c"} {
+		mainc, combc, style, _ := s.GetContent(w-3+i, 0)
+		if got := string(append([]rune{mainc}, combc...)); got != want {
+			t.Errorf("Cell %d: expected %q, got %q", i, want, got)
+		}
+		if i == 0 && style != red {
+			t.Errorf("Style not set")
+		}
+	}
+}
+
+func TestFrame(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	s.BeginFrame()
+	s.PutString(0, 0, "abc", StyleDefault)
+	s.Show()
+	b, _, _ := s.GetContents()
+	if string(b[0].Runes) == "a" {
+		t.Errorf("Frame shown before it ended")
+	}
+	s.EndFrame()
+	b, _, _ = s.GetContents()
+	if string(b[0].Runes) != "a" {
+		t.Errorf("Frame not shown when it ended: %q", string(b[0].Runes))
+	}
+
+	// Frames from several goroutines are shown together.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		s.BeginFrame()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer s.EndFrame()
+			s.SetContent(i, 1, 'x', nil, StyleDefault)
+			s.Show()
+		}(i)
+	}
+	wg.Wait()
+	b, w, _ := s.GetContents()
+	for i := 0; i < 4; i++ {
+		if string(b[w+i].Runes) != "x" {
+			t.Errorf("Cell %d not shown", i)
+		}
+	}
+}
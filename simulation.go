@@ -15,6 +15,7 @@
 package tcell
 
 import (
+	"image"
 	"sync"
 	"unicode/utf8"
 
@@ -52,6 +53,10 @@ type SimulationScreen interface {
 	// InjectResize injects a resize event
 	InjectResize()
 
+	// InjectFocus injects a focus event.  It is delivered even if
+	// EnableFocus has not been called.
+	InjectFocus(focused bool)
+
 	// SetSize resizes the underlying physical screen.  It also causes
 	// a resize event to be injected during the next Show() or Sync().
 	// A new physical contents array will be allocated (with data from
@@ -68,6 +73,20 @@ type SimulationScreen interface {
 	// GetCursor returns the cursor details.
 	GetCursor() (x int, y int, visible bool)
 
+	// GetCursorStyle returns the cursor shape and color, as set by
+	// SetCursorStyle and SetCursorColor.
+	GetCursorStyle() (style CursorStyle, color Color)
+
+	// GetTitle returns the window title most recently set.
+	GetTitle() string
+
+	// GetIconName returns the icon name most recently set.
+	GetIconName() string
+
+	// GetImages returns the images that have been placed with
+	// ShowImage, and not yet removed.
+	GetImages() []SimImage
+
 	Screen
 }
 
@@ -85,6 +104,16 @@ type SimCell struct {
 	Runes []rune
 }
 
+// SimImage represents an image placed on a simulated screen.
+type SimImage struct {
+	// X, Y, Width and Height are the cells covered by the image.
+	X, Y          int
+	Width, Height int
+
+	// Image is the image itself.
+	Image image.Image
+}
+
 type simscreen struct {
 	physw int
 	physh int
@@ -106,6 +135,14 @@ type simscreen struct {
 	fillchar  rune
 	fillstyle Style
 	fallback  map[rune]string
+	suspended bool
+	title     string
+	icon      string
+	curstyle  CursorStyle
+	curcolor  Color
+	images    []SimImage
+	palette   map[int]Color
+	frame     frameState
 
 	sync.Mutex
 }
@@ -144,6 +181,10 @@ func (s *simscreen) Fini() {
 	s.Lock()
 	s.fini = true
 	s.back.Resize(0, 0)
+	s.curstyle = CursorStyleDefault
+	s.curcolor = ColorDefault
+	s.images = nil
+	s.palette = nil
 	s.Unlock()
 	if s.quit != nil {
 		close(s.quit)
@@ -161,6 +202,7 @@ func (s *simscreen) SetStyle(style Style) {
 
 func (s *simscreen) Clear() {
 	s.Fill(' ', s.style)
+	s.ClearImages()
 }
 
 func (s *simscreen) Fill(r rune, style Style) {
@@ -169,6 +211,18 @@ func (s *simscreen) Fill(r rune, style Style) {
 	s.Unlock()
 }
 
+func (s *simscreen) ScrollRegion(top, bottom, n int) {
+	s.Lock()
+	defer s.Unlock()
+	w, _ := s.back.Size()
+	s.back.ScrollRegion(top, bottom, n, s.style)
+	for y := top; y <= bottom; y++ {
+		for x := 0; x < w; x++ {
+			s.back.SetDirty(x, y, true)
+		}
+	}
+}
+
 func (s *simscreen) SetCell(x, y int, style Style, ch ...rune) {
 
 	if len(ch) > 0 {
@@ -185,6 +239,36 @@ func (s *simscreen) SetContent(x, y int, mainc rune, combc []rune, st Style) {
 	s.Unlock()
 }
 
+func (s *simscreen) SetWidthPolicy(policy WidthPolicy) {
+	s.Lock()
+	s.back.SetWidthPolicy(policy)
+	s.Unlock()
+}
+
+func (s *simscreen) WidthPolicy() WidthPolicy {
+	s.Lock()
+	defer s.Unlock()
+	return s.back.WidthPolicy()
+}
+
+func (s *simscreen) SetContentString(x, y int, cluster string, style Style) {
+	s.Lock()
+	s.back.SetContentString(x, y, cluster, style)
+	s.Unlock()
+}
+
+func (s *simscreen) SetContents(x, y int, cells []Cell) {
+	s.Lock()
+	s.back.SetContents(x, y, cells)
+	s.Unlock()
+}
+
+func (s *simscreen) PutString(x, y int, str string, style Style) int {
+	s.Lock()
+	defer s.Unlock()
+	return s.back.PutString(x, y, str, style)
+}
+
 func (s *simscreen) GetContent(x, y int) (rune, []rune, Style, int) {
 	var mainc rune
 	var combc []rune
@@ -285,11 +369,19 @@ func (s *simscreen) hideCursor() {
 
 func (s *simscreen) Show() {
 	s.Lock()
-	s.resize()
-	s.draw()
+	if !s.frame.deferDraw(frameShow) {
+		s.show()
+	}
 	s.Unlock()
 }
 
+func (s *simscreen) show() {
+	if !s.suspended {
+		s.resize()
+		s.draw()
+	}
+}
+
 func (s *simscreen) clearScreen() {
 	// We emulate a hardware clear by filling with a specific pattern
 	for i := range s.front {
@@ -324,6 +416,14 @@ func (s *simscreen) DisableMouse() {
 	s.mouse = false
 }
 
+func (s *simscreen) EnableFocus()  {}
+func (s *simscreen) DisableFocus() {}
+
+func (s *simscreen) EnableKittyKeyboard(KeyboardFlags) {}
+func (s *simscreen) DisableKittyKeyboard()             {}
+func (s *simscreen) EnableModifyOtherKeys()            {}
+func (s *simscreen) DisableModifyOtherKeys()           {}
+
 func (s *simscreen) Size() (int, int) {
 	s.Lock()
 	w, h := s.back.Size()
@@ -424,6 +524,11 @@ outer:
 	return !failed
 }
 
+func (s *simscreen) InjectFocus(focused bool) {
+	ev := NewEventFocus(focused, "")
+	s.PostEvent(ev)
+}
+
 func (s *simscreen) InjectResize() {
 	w, h := s.physw, s.physh
 	ev := NewEventResize(w, h)
@@ -432,11 +537,43 @@ func (s *simscreen) InjectResize() {
 
 func (s *simscreen) Sync() {
 	s.Lock()
+	if !s.suspended && !s.frame.deferDraw(frameSync) {
+		s.sync()
+	}
+	s.Unlock()
+}
+
+func (s *simscreen) SetMaxFPS(int) {}
+
+func (s *simscreen) ShowNow() {
+	s.Show()
+}
+
+func (s *simscreen) BeginFrame() {
+	s.Lock()
+	s.frame.begin()
+	s.Unlock()
+}
+
+func (s *simscreen) EndFrame() {
+	s.Lock()
+	switch s.frame.end() {
+	case frameShow:
+		s.show()
+	case frameRedraw, frameSync:
+		if !s.suspended {
+			s.sync()
+		}
+	}
+	s.Unlock()
+}
+
+func (s *simscreen) sync() {
+	s.images = nil
 	s.clear = true
 	s.resize()
 	s.back.Invalidate()
 	s.draw()
-	s.Unlock()
 }
 
 func (s *simscreen) CharacterSet() string {
@@ -472,6 +609,119 @@ func (s *simscreen) GetCursor() (int, int, bool) {
 	return x, y, vis
 }
 
+func (s *simscreen) GetCursorStyle() (CursorStyle, Color) {
+	s.Lock()
+	cs, cc := s.curstyle, s.curcolor
+	s.Unlock()
+	return cs, cc
+}
+
+func (s *simscreen) SetCursorStyle(cs CursorStyle) {
+	s.Lock()
+	s.curstyle = cs
+	s.Unlock()
+}
+
+func (s *simscreen) SetCursorColor(c Color) {
+	s.Lock()
+	s.curcolor = c
+	s.Unlock()
+}
+
+func (s *simscreen) ShowImage(x, y, w, h int, img image.Image) error {
+	if x < 0 || y < 0 {
+		return ErrOffScreen
+	}
+	s.Lock()
+	if w > 0 && h > 0 {
+		s.images = append(s.images, SimImage{x, y, w, h, img})
+	}
+	s.Unlock()
+	return nil
+}
+
+func (s *simscreen) ClearImages() {
+	s.Lock()
+	s.images = nil
+	s.Unlock()
+}
+
+// Probe returns ErrNotSupported, as the simulation cannot be asked.
+func (s *simscreen) Probe() error {
+	return ErrNotSupported
+}
+
+// Capabilities returns nothing, as the simulation is not probed.
+func (s *simscreen) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (s *simscreen) QueryDefaultColors() (Color, Color, error) {
+	return ColorDefault, ColorDefault, ErrNotSupported
+}
+
+// QueryPalette returns the standard palette, with any colors changed by
+// SetPaletteColor.
+func (s *simscreen) QueryPalette() ([]Color, error) {
+	s.Lock()
+	defer s.Unlock()
+	pal := make([]Color, s.Colors())
+	for i := range pal {
+		if c, ok := s.palette[i]; ok {
+			pal[i] = c
+		} else {
+			pal[i] = (Color(i) | ColorValid).TrueColor()
+		}
+	}
+	return pal, nil
+}
+
+func (s *simscreen) SetPaletteColor(index int, c Color) {
+	s.Lock()
+	defer s.Unlock()
+	if index < 0 || index >= s.Colors() {
+		return
+	}
+	if s.palette == nil {
+		s.palette = make(map[int]Color)
+	}
+	if c.Valid() {
+		s.palette[index] = c.TrueColor()
+	} else {
+		delete(s.palette, index)
+	}
+}
+
+func (s *simscreen) GetImages() []SimImage {
+	s.Lock()
+	defer s.Unlock()
+	return append([]SimImage(nil), s.images...)
+}
+
+func (s *simscreen) GetTitle() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.title
+}
+
+func (s *simscreen) GetIconName() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.icon
+}
+
+func (s *simscreen) SetTitle(title string) {
+	s.Lock()
+	s.title = title
+	s.Unlock()
+}
+
+func (s *simscreen) SetIconName(name string) {
+	s.Lock()
+	s.icon = name
+	s.Unlock()
+}
+
 func (s *simscreen) RegisterRuneFallback(r rune, subst string) {
 	s.Lock()
 	s.fallback[r] = subst
@@ -522,3 +772,23 @@ func (s *simscreen) SetPaste(bool)         {}
 func (s *simscreen) GetClipboard(string) error         { return nil }
 func (s *simscreen) SetClipboard(string, string) error { return nil }
 func (s *simscreen) Beep() error                       { return nil }
+
+func (s *simscreen) Suspend() error {
+	s.Lock()
+	s.suspended = true
+	s.hideCursor()
+	s.Unlock()
+	return nil
+}
+
+func (s *simscreen) Resume() error {
+	s.Lock()
+	if s.suspended {
+		s.suspended = false
+		if !s.frame.deferDraw(frameSync) {
+			s.sync()
+		}
+	}
+	s.Unlock()
+	return nil
+}
@@ -33,9 +33,13 @@ package tcell
 //
 // To use Style, just declare a variable of its type.
 type Style struct {
-	fg       Color
-	bg       Color
-	attrs    AttrMask
+	fg      Color
+	bg      Color
+	attrs   AttrMask
+	ulStyle UnderlineStyle
+	ulColor Color
+	url     string
+	urlId   string
 }
 
 // StyleDefault represents a default style, based upon the context.
@@ -49,9 +53,13 @@ var styleInvalid = Style{attrs: AttrInvalid}
 // as requested.  ColorDefault can be used to select the global default.
 func (s Style) Foreground(c Color) Style {
 	return Style{
-		fg:    c,
-		bg:    s.bg,
-		attrs: s.attrs,
+		fg:      c,
+		bg:      s.bg,
+		attrs:   s.attrs,
+		ulStyle: s.ulStyle,
+		ulColor: s.ulColor,
+		url:     s.url,
+		urlId:   s.urlId,
 	}
 }
 
@@ -59,9 +67,13 @@ func (s Style) Foreground(c Color) Style {
 // as requested.  ColorDefault can be used to select the global default.
 func (s Style) Background(c Color) Style {
 	return Style{
-		fg:    s.fg,
-		bg:    c,
-		attrs: s.attrs,
+		fg:      s.fg,
+		bg:      c,
+		attrs:   s.attrs,
+		ulStyle: s.ulStyle,
+		ulColor: s.ulColor,
+		url:     s.url,
+		urlId:   s.urlId,
 	}
 }
 
@@ -71,26 +83,61 @@ func (s Style) Decompose() (fg Color, bg Color, attr AttrMask) {
 	return s.fg, s.bg, s.attrs
 }
 
+// DecomposeUnderline returns the underline style and color of the
+// style.  The style is UnderlineStyleNone if the text is not underlined,
+// and the color is ColorDefault if the underline uses the color of the
+// text.
+func (s Style) DecomposeUnderline() (UnderlineStyle, Color) {
+	if s.attrs&AttrUnderline == 0 {
+		return UnderlineStyleNone, s.ulColor
+	}
+	if s.ulStyle == UnderlineStyleNone {
+		return UnderlineStyleSolid, s.ulColor
+	}
+	return s.ulStyle, s.ulColor
+}
+
+// DecomposeUrl returns the hyperlink target of the style, and its id,
+// both of which are empty if the style is not a link.
+func (s Style) DecomposeUrl() (url string, id string) {
+	return s.url, s.urlId
+}
+
 func (s Style) setAttrs(attrs AttrMask, on bool) Style {
 	if on {
 		return Style{
-			fg:    s.fg,
-			bg:    s.bg,
-			attrs: s.attrs | attrs,
+			fg:      s.fg,
+			bg:      s.bg,
+			attrs:   s.attrs | attrs,
+			ulStyle: s.ulStyle,
+			ulColor: s.ulColor,
+			url:     s.url,
+			urlId:   s.urlId,
 		}
 	}
+	ulStyle := s.ulStyle
+	if attrs&AttrUnderline != 0 {
+		ulStyle = UnderlineStyleNone
+	}
 	return Style{
-		fg:    s.fg,
-		bg:    s.bg,
-		attrs: s.attrs &^ attrs,
+		fg:      s.fg,
+		bg:      s.bg,
+		attrs:   s.attrs &^ attrs,
+		ulStyle: ulStyle,
+		ulColor: s.ulColor,
+		url:     s.url,
+		urlId:   s.urlId,
 	}
 }
 
 // Normal returns the style with all attributes disabled.
 func (s Style) Normal() Style {
 	return Style{
-		fg: s.fg,
-		bg: s.bg,
+		fg:      s.fg,
+		bg:      s.bg,
+		ulColor: s.ulColor,
+		url:     s.url,
+		urlId:   s.urlId,
 	}
 }
 
@@ -131,7 +178,43 @@ func (s Style) Underline(on bool) Style {
 	return s.setAttrs(AttrUnderline, on)
 }
 
+// UnderlineStyle returns a new style based on s, underlined with the
+// given style of line.  UnderlineStyleNone turns off the underline.
+func (s Style) UnderlineStyle(us UnderlineStyle) Style {
+	if us == UnderlineStyleNone {
+		return s.setAttrs(AttrUnderline, false)
+	}
+	s = s.setAttrs(AttrUnderline, true)
+	s.ulStyle = us
+	return s
+}
+
+// UnderlineColor returns a new style based on s, with the color of the
+// underline set as requested.  ColorDefault uses the color of the text.
+// This does not itself turn on the underline.
+func (s Style) UnderlineColor(c Color) Style {
+	s.ulColor = c
+	return s
+}
+
 // StrikeThrough sets strikethrough mode.
 func (s Style) StrikeThrough(on bool) Style {
 	return s.setAttrs(AttrStrikeThrough, on)
 }
+
+// Url returns a new style based on s, that makes the text a hyperlink to
+// the given URL on terminals that support it (using OSC 8).  An empty
+// string removes the link.
+func (s Style) Url(url string) Style {
+	s.url = url
+	return s
+}
+
+// UrlId returns a new style based on s, with the id of the hyperlink set
+// as requested.  Adjacent text with the same id and URL is treated as a
+// single link by the terminal (for example when highlighting it), even
+// if it is drawn in pieces, such as when it wraps onto another line.
+func (s Style) UrlId(id string) Style {
+	s.urlId = id
+	return s
+}
@@ -39,3 +39,47 @@ func TestStyle(t *testing.T) {
 		t.Errorf("Bad custom style (%v, %v, %v)", fg, bg, attr)
 	}
 }
+
+func TestStyleUnderline(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	if us, uc := StyleDefault.DecomposeUnderline(); us != UnderlineStyleNone || uc != ColorDefault {
+		t.Errorf("Bad default underline (%v, %v)", us, uc)
+	}
+	if us, _ := StyleDefault.Underline(true).DecomposeUnderline(); us != UnderlineStyleSolid {
+		t.Errorf("Bad plain underline (%v)", us)
+	}
+
+	style := StyleDefault.UnderlineStyle(UnderlineStyleCurly).UnderlineColor(ColorRed)
+	s.SetContent(0, 0, 'x', nil, style)
+	s.Show()
+	cells, _, _ := s.GetContents()
+	us, uc := cells[0].Style.DecomposeUnderline()
+	if us != UnderlineStyleCurly || uc != ColorRed {
+		t.Errorf("Bad curly underline (%v, %v)", us, uc)
+	}
+	if _, _, attr := cells[0].Style.Decompose(); attr != AttrUnderline {
+		t.Errorf("Underline attribute not set (%v)", attr)
+	}
+
+	if us, uc = cells[0].Style.Underline(false).DecomposeUnderline(); us != UnderlineStyleNone || uc != ColorRed {
+		t.Errorf("Bad cleared underline (%v, %v)", us, uc)
+	}
+}
+
+func TestStyleUrl(t *testing.T) {
+	s := mkTestScreen(t, "")
+	defer s.Fini()
+
+	style := StyleDefault.Foreground(ColorBlue).Url("https://example.com").UrlId("1")
+	s.SetContent(0, 0, 'x', nil, style.Bold(true))
+	s.Show()
+	cells, _, _ := s.GetContents()
+	if url, id := cells[0].Style.DecomposeUrl(); url != "https://example.com" || id != "1" {
+		t.Errorf("Bad link (%v, %v)", url, id)
+	}
+	if url, id := cells[0].Style.Url("").DecomposeUrl(); url != "" || id != "1" {
+		t.Errorf("Bad cleared link (%v, %v)", url, id)
+	}
+}
@@ -12,6 +12,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        256,
+		BgColorErase:  true,
 		Bell:          "\a",
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b[?1049h\x1b[22;0;0t",
@@ -40,6 +41,9 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
+		RepeatChar:    "%p1%c\x1b[%p2%{1}%-%db",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -30,6 +30,9 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\x1b[D",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
+		EraseChars:   "\x1b[%p1%dX",
+		RepeatChar:   "%p1%c\x1b[%p2%{1}%-%db",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
@@ -29,6 +29,7 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
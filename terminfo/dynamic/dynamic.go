@@ -156,6 +156,7 @@ func LoadTerminfo(name string) (*terminfo.Terminfo, string, error) {
 	t.Modifiers = terminfo.ModifiersDynamic
 	t.Aliases = tc.aliases
 	t.Colors = tc.getnum("colors")
+	t.BgColorErase = tc.getflag("bce")
 	t.Columns = tc.getnum("cols")
 	t.Lines = tc.getnum("lines")
 	t.Bell = tc.getstr("bel")
@@ -178,6 +179,33 @@ func LoadTerminfo(name string) (*terminfo.Terminfo, string, error) {
 	t.SetCursor = tc.getstr("cup")
 	t.CursorBack1 = tc.getstr("cub1")
 	t.CursorUp1 = tc.getstr("cuu1")
+	t.CursorUp = tc.getstr("cuu")
+	t.CursorDown = tc.getstr("cud")
+	t.CursorRight = tc.getstr("cuf")
+	t.CursorDown1 = tc.getstr("cud1")
+	t.CursorRight1 = tc.getstr("cuf1")
+	t.CursorBack = tc.getstr("cub")
+	t.CarriageRet = tc.getstr("cr")
+	t.ClearEOL = tc.getstr("el")
+	t.ClearEOS = tc.getstr("ed")
+	t.ScrollRegion = tc.getstr("csr")
+	t.ScrollFwd = tc.getstr("ind")
+	t.ScrollRev = tc.getstr("ri")
+	t.InsertLines = tc.getstr("il")
+	t.DeleteLines = tc.getstr("dl")
+	t.EraseChars = tc.getstr("ech")
+	t.RepeatChar = tc.getstr("rep")
+	t.InsertChars = tc.getstr("ich")
+	t.DeleteChars = tc.getstr("dch")
+	t.EnterInsert = tc.getstr("smir")
+	t.ExitInsert = tc.getstr("rmir")
+	t.EnterTitle = tc.getstr("tsl")
+	t.ExitTitle = tc.getstr("fsl")
+	t.SetCursorStyle = tc.getstr("Ss")
+	t.ResetCursor = tc.getstr("Se")
+	t.Sync = tc.getstr("Sync")
+	t.UnderlineStyle = tc.getstr("Smulx")
+	t.UnderlineColor = tc.getstr("Setulc")
 	t.KeyF1 = tc.getstr("kf1")
 	t.KeyF2 = tc.getstr("kf2")
 	t.KeyF3 = tc.getstr("kf3")
@@ -23,6 +23,7 @@ func init() {
 		SetCursor:   "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1: "\b",
 		CursorUp1:   "\x1b[A",
+		ClearEOL:    "\x1b[K",
 	})
 
 	// Emacs term.el terminal emulator term-protocol-version 0.96
@@ -46,6 +47,7 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
 		KeyUp:        "\x1bOA",
 		KeyDown:      "\x1bOB",
 		KeyRight:     "\x1bOC",
@@ -27,6 +27,7 @@ func init() {
 		SetCursor:    "\x1b&a%p1%dy%p2%dC",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1bA",
+		ClearEOL:     "\x1bK",
 		KeyUp:        "\x1bA",
 		KeyDown:      "\x1bB",
 		KeyRight:     "\x1bC",
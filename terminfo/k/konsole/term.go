@@ -12,6 +12,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        8,
+		BgColorErase:  true,
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b7\x1b[?47h",
 		ExitCA:        "\x1b[2J\x1b[?47l\x1b8",
@@ -40,6 +41,8 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -73,6 +76,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        256,
+		BgColorErase:  true,
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b7\x1b[?47h",
 		ExitCA:        "\x1b[2J\x1b[?47l\x1b8",
@@ -101,6 +105,8 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -10,6 +10,7 @@ func init() {
 	terminfo.AddTerminfo(&terminfo.Terminfo{
 		Name:         "linux",
 		Colors:       8,
+		BgColorErase: true,
 		Bell:         "\a",
 		Clear:        "\x1b[H\x1b[J",
 		ShowCursor:   "\x1b[?25h\x1b[?0c",
@@ -34,6 +35,8 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
+		EraseChars:   "\x1b[%p1%dX",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
@@ -215,6 +215,7 @@ func getinfo(name string) (*terminfo.Terminfo, string, error) {
 	}
 	t.Aliases = tc.aliases
 	t.Colors = tc.getnum("colors")
+	t.BgColorErase = tc.getflag("bce")
 	t.Columns = tc.getnum("cols")
 	t.Lines = tc.getnum("lines")
 	t.Bell = tc.getstr("bel")
@@ -238,6 +239,33 @@ func getinfo(name string) (*terminfo.Terminfo, string, error) {
 	t.SetCursor = tc.getstr("cup")
 	t.CursorBack1 = tc.getstr("cub1")
 	t.CursorUp1 = tc.getstr("cuu1")
+	t.CursorUp = tc.getstr("cuu")
+	t.CursorDown = tc.getstr("cud")
+	t.CursorRight = tc.getstr("cuf")
+	t.CursorDown1 = tc.getstr("cud1")
+	t.CursorRight1 = tc.getstr("cuf1")
+	t.CursorBack = tc.getstr("cub")
+	t.CarriageRet = tc.getstr("cr")
+	t.ClearEOL = tc.getstr("el")
+	t.ClearEOS = tc.getstr("ed")
+	t.ScrollRegion = tc.getstr("csr")
+	t.ScrollFwd = tc.getstr("ind")
+	t.ScrollRev = tc.getstr("ri")
+	t.InsertLines = tc.getstr("il")
+	t.DeleteLines = tc.getstr("dl")
+	t.EraseChars = tc.getstr("ech")
+	t.RepeatChar = tc.getstr("rep")
+	t.InsertChars = tc.getstr("ich")
+	t.DeleteChars = tc.getstr("dch")
+	t.EnterInsert = tc.getstr("smir")
+	t.ExitInsert = tc.getstr("rmir")
+	t.EnterTitle = tc.getstr("tsl")
+	t.ExitTitle = tc.getstr("fsl")
+	t.SetCursorStyle = tc.getstr("Ss")
+	t.ResetCursor = tc.getstr("Se")
+	t.Sync = tc.getstr("Sync")
+	t.UnderlineStyle = tc.getstr("Smulx")
+	t.UnderlineColor = tc.getstr("Setulc")
 	t.KeyF1 = tc.getstr("kf1")
 	t.KeyF2 = tc.getstr("kf2")
 	t.KeyF3 = tc.getstr("kf3")
@@ -494,6 +522,7 @@ func dotGoInfo(w io.Writer, terms []*TData) {
 		dotGoAddInt(w, "Columns", t.Columns)
 		dotGoAddInt(w, "Lines", t.Lines)
 		dotGoAddInt(w, "Colors", t.Colors)
+		dotGoAddFlag(w, "BgColorErase", t.BgColorErase)
 		dotGoAddStr(w, "Bell", t.Bell)
 		dotGoAddStr(w, "Clear", t.Clear)
 		dotGoAddStr(w, "EnterCA", t.EnterCA)
@@ -527,6 +556,33 @@ func dotGoInfo(w io.Writer, terms []*TData) {
 		dotGoAddStr(w, "SetCursor", t.SetCursor)
 		dotGoAddStr(w, "CursorBack1", t.CursorBack1)
 		dotGoAddStr(w, "CursorUp1", t.CursorUp1)
+		dotGoAddStr(w, "CursorUp", t.CursorUp)
+		dotGoAddStr(w, "CursorDown", t.CursorDown)
+		dotGoAddStr(w, "CursorRight", t.CursorRight)
+		dotGoAddStr(w, "CursorDown1", t.CursorDown1)
+		dotGoAddStr(w, "CursorRight1", t.CursorRight1)
+		dotGoAddStr(w, "CursorBack", t.CursorBack)
+		dotGoAddStr(w, "CarriageRet", t.CarriageRet)
+		dotGoAddStr(w, "ClearEOL", t.ClearEOL)
+		dotGoAddStr(w, "ClearEOS", t.ClearEOS)
+		dotGoAddStr(w, "ScrollRegion", t.ScrollRegion)
+		dotGoAddStr(w, "ScrollFwd", t.ScrollFwd)
+		dotGoAddStr(w, "ScrollRev", t.ScrollRev)
+		dotGoAddStr(w, "InsertLines", t.InsertLines)
+		dotGoAddStr(w, "DeleteLines", t.DeleteLines)
+		dotGoAddStr(w, "EraseChars", t.EraseChars)
+		dotGoAddStr(w, "RepeatChar", t.RepeatChar)
+		dotGoAddStr(w, "InsertChars", t.InsertChars)
+		dotGoAddStr(w, "DeleteChars", t.DeleteChars)
+		dotGoAddStr(w, "EnterInsert", t.EnterInsert)
+		dotGoAddStr(w, "ExitInsert", t.ExitInsert)
+		dotGoAddStr(w, "EnterTitle", t.EnterTitle)
+		dotGoAddStr(w, "ExitTitle", t.ExitTitle)
+		dotGoAddStr(w, "SetCursorStyle", t.SetCursorStyle)
+		dotGoAddStr(w, "ResetCursor", t.ResetCursor)
+		dotGoAddStr(w, "Sync", t.Sync)
+		dotGoAddStr(w, "UnderlineStyle", t.UnderlineStyle)
+		dotGoAddStr(w, "UnderlineColor", t.UnderlineColor)
 		dotGoAddStr(w, "KeyUp", t.KeyUp)
 		dotGoAddStr(w, "KeyDown", t.KeyDown)
 		dotGoAddStr(w, "KeyRight", t.KeyRight)
@@ -12,6 +12,7 @@ func init() {
 		Columns:      80,
 		Lines:        24,
 		Colors:       8,
+		BgColorErase: true,
 		Bell:         "\a",
 		Clear:        "\x1b[H\x1b[2J",
 		EnterCA:      "\x1b7\x1b[?47h",
@@ -39,6 +40,7 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
@@ -116,6 +118,7 @@ func init() {
 		Columns:      80,
 		Lines:        24,
 		Colors:       256,
+		BgColorErase: true,
 		Bell:         "\a",
 		Clear:        "\x1b[H\x1b[2J",
 		EnterCA:      "\x1b7\x1b[?47h",
@@ -143,6 +146,7 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
@@ -220,6 +224,7 @@ func init() {
 		Columns:      80,
 		Lines:        24,
 		Colors:       88,
+		BgColorErase: true,
 		Bell:         "\a",
 		Clear:        "\x1b[H\x1b[2J",
 		EnterCA:      "\x1b7\x1b[?47h",
@@ -247,6 +252,7 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
@@ -324,6 +330,7 @@ func init() {
 		Columns:      80,
 		Lines:        24,
 		Colors:       88,
+		BgColorErase: true,
 		Bell:         "\a",
 		Clear:        "\x1b[H\x1b[2J",
 		EnterCA:      "\x1b[?1049h",
@@ -350,6 +357,8 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
+		EraseChars:   "\x1b[%p1%dX",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
@@ -404,6 +413,7 @@ func init() {
 		Columns:      80,
 		Lines:        24,
 		Colors:       256,
+		BgColorErase: true,
 		Bell:         "\a",
 		Clear:        "\x1b[H\x1b[2J",
 		EnterCA:      "\x1b[?1049h",
@@ -430,6 +440,8 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K",
+		EraseChars:   "\x1b[%p1%dX",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
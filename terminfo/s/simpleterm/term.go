@@ -12,6 +12,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        8,
+		BgColorErase:  true,
 		Bell:          "\a",
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b[?1049h",
@@ -41,6 +42,8 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -76,6 +79,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        256,
+		BgColorErase:  true,
 		Bell:          "\a",
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b[?1049h",
@@ -105,6 +109,8 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -42,6 +42,7 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1bM",
+		ClearEOL:      "\x1b[K",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -46,6 +46,7 @@ type Terminfo struct {
 	Columns      int    // cols
 	Lines        int    // lines
 	Colors       int    // colors
+	BgColorErase bool   // bce
 	Bell         string // bell
 	Clear        string // clear
 	EnterCA      string // smcup
@@ -67,6 +68,28 @@ type Terminfo struct {
 	SetCursor    string // cup
 	CursorBack1  string // cub1
 	CursorUp1    string // cuu1
+	CursorUp     string // cuu
+	CursorDown   string // cud
+	CursorRight  string // cuf
+	CursorDown1  string // cud1
+	CursorRight1 string // cuf1
+	CursorBack   string // cub
+	CarriageRet  string // cr
+	ClearEOL     string // el
+	ClearEOS     string // ed
+	ScrollRegion string // csr
+	ScrollFwd    string // ind
+	ScrollRev    string // ri
+	InsertLines  string // il
+	DeleteLines  string // dl
+	EraseChars   string // ech
+	RepeatChar   string // rep
+	InsertChars  string // ich
+	DeleteChars  string // dch
+	EnterInsert  string // smir
+	ExitInsert   string // rmir
+	EnterTitle   string // tsl
+	ExitTitle    string // fsl
 	PadChar      string // pad
 	KeyBackspace string // kbs
 	KeyF1        string // kf1
@@ -169,6 +192,11 @@ type Terminfo struct {
 	// emulations, so don't depend too much on them in your application.
 
 	StrikeThrough   string // smxx
+	SetCursorStyle  string // Ss
+	ResetCursor     string // Se
+	Sync            string // Sync
+	UnderlineStyle  string // Smulx
+	UnderlineColor  string // Setulc
 	SetFgBg         string // setfgbg
 	SetFgBgRGB      string // setfgbgrgb
 	SetFgRGB        string // setfrgb
@@ -405,8 +433,19 @@ func (t *Terminfo) TParm(s string, p ...int) string {
 			params[0]++
 			params[1]++
 
-		case 'c', 's':
-			// NB: these, and 'd' below are special cased for
+		case 'c':
+			// An integer is output as the character it encodes
+			// (as with rep), and a character as itself.
+			if len(stk) > 0 && stk[len(stk)-1].isInt {
+				ai, stk = stk.PopInt()
+				pb.PutString(string(rune(ai)))
+			} else {
+				a, stk = stk.Pop()
+				pb.PutString(a)
+			}
+
+		case 's':
+			// NB: this, and 'd' below are special cased for
 			// efficiency.  They could be handled by the richer
 			// format support below, less efficiently.
 			a, stk = stk.Pop()
@@ -70,6 +70,11 @@ func TestTerminfoExpansion(t *testing.T) {
 	if ti.TParm(ti.MouseMode, 0) != "\x1b[?1000l\x1b[?1002l\x1b[?1006l" {
 		t.Error("Disable mouse mode failed")
 	}
+
+	// Characters given as integers, as with rep.
+	if ti.TParm("%p1%c\x1b[%p2%{1}%-%db", 'x', 5) != "x\x1b[4b" {
+		t.Error("Repeat character failed")
+	}
 }
 
 func TestTerminfoDelay(t *testing.T) {
@@ -28,6 +28,7 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH$<5>",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A$<2>",
+		ClearEOL:     "\x1b[K$<3>",
 		KeyUp:        "\x1bOA",
 		KeyDown:      "\x1bOB",
 		KeyRight:     "\x1bOC",
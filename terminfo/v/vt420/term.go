@@ -30,6 +30,8 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH$<10>",
 		CursorBack1:  "\b",
 		CursorUp1:    "\x1b[A",
+		ClearEOL:     "\x1b[K$<3>",
+		EraseChars:   "\x1b[%p1%dX",
 		KeyUp:        "\x1b[A",
 		KeyDown:      "\x1b[B",
 		KeyRight:     "\x1b[C",
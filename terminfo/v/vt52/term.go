@@ -20,6 +20,7 @@ func init() {
 		SetCursor:    "\x1bY%p1%' '%+%c%p2%' '%+%c",
 		CursorBack1:  "\x1bD",
 		CursorUp1:    "\x1bA",
+		ClearEOL:     "\x1bK",
 		KeyUp:        "\x1bA",
 		KeyDown:      "\x1bB",
 		KeyRight:     "\x1bC",
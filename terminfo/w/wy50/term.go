@@ -26,6 +26,7 @@ func init() {
 		SetCursor:    "\x1b=%p1%' '%+%c%p2%' '%+%c",
 		CursorBack1:  "\b",
 		CursorUp1:    "\v",
+		ClearEOL:     "\x1bT",
 		KeyUp:        "\v",
 		KeyDown:      "\n",
 		KeyRight:     "\f",
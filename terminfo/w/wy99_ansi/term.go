@@ -31,6 +31,8 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b$<1>",
 		CursorUp1:    "\x1bM",
+		ClearEOL:     "\x1b[K$<1>",
+		EraseChars:   "\x1b[%p1%dX",
 		KeyUp:        "\x1bOA",
 		KeyDown:      "\x1bOB",
 		KeyRight:     "\x1bOC",
@@ -84,6 +86,8 @@ func init() {
 		SetCursor:    "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:  "\b$<1>",
 		CursorUp1:    "\x1bM",
+		ClearEOL:     "\x1b[K$<1>",
+		EraseChars:   "\x1b[%p1%dX",
 		KeyUp:        "\x1bOA",
 		KeyDown:      "\x1bOB",
 		KeyRight:     "\x1bOC",
@@ -13,6 +13,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        8,
+		BgColorErase:  true,
 		Bell:          "\a",
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b[?1049h\x1b[22;0;0t",
@@ -41,6 +42,9 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
+		RepeatChar:    "%p1%c\x1b[%p2%{1}%-%db",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -74,6 +78,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        88,
+		BgColorErase:  true,
 		Bell:          "\a",
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b[?1049h\x1b[22;0;0t",
@@ -102,6 +107,9 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
+		RepeatChar:    "%p1%c\x1b[%p2%{1}%-%db",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
@@ -135,6 +143,7 @@ func init() {
 		Columns:       80,
 		Lines:         24,
 		Colors:        256,
+		BgColorErase:  true,
 		Bell:          "\a",
 		Clear:         "\x1b[H\x1b[2J",
 		EnterCA:       "\x1b[?1049h\x1b[22;0;0t",
@@ -163,6 +172,9 @@ func init() {
 		SetCursor:     "\x1b[%i%p1%d;%p2%dH",
 		CursorBack1:   "\b",
 		CursorUp1:     "\x1b[A",
+		ClearEOL:      "\x1b[K",
+		EraseChars:    "\x1b[%p1%dX",
+		RepeatChar:    "%p1%c\x1b[%p2%{1}%-%db",
 		KeyUp:         "\x1bOA",
 		KeyDown:       "\x1bOB",
 		KeyRight:      "\x1bOC",
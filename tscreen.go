@@ -25,6 +25,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"golang.org/x/text/transform"
@@ -49,8 +50,67 @@ const (
 
 	pasteOSC52Begin = "\x1b]52;"
 	pasteOSC52End   = "\x1b\\"
+)
+
+// Focus reporting (XTerm mode 1004).
+const (
+	focusEnable  = "\x1b[?1004h"
+	focusDisable = "\x1b[?1004l"
+)
+
+// Synchronized output (mode 2026).  While this mode is set, the terminal
+// defers rendering, so that a frame is displayed all at once.  It is
+// used if terminfo says so, or if the terminal recognizes the mode when
+// it is probed.
+const (
+	syncBegin = "\x1b[?2026h"
+	syncEnd   = "\x1b[?2026l"
+)
+
+// Kitty keyboard protocol.  We push our flags on the terminal's stack
+// of keyboard modes, and pop them when we are done.
+// See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+const (
+	kittyKeyPush = "\x1b[>%du"
+	kittyKeyPop  = "\x1b[<u"
+)
 
-	setTitle = "\x1b]2;title\a"
+// Underline styles and colors.  These are the sequences used when the
+// terminal is known to support them but terminfo does not describe them.
+const (
+	underStyleSet   = "\x1b[4:%p1%dm"
+	underColorSet   = "\x1b[58:2::%p1%{65536}%/%d:%p1%{256}%/%{255}%&%d:%p1%{255}%&%dm"
+	underColorIndex = "\x1b[58:5:%dm"
+)
+
+// Hyperlinks (OSC 8).  The parameters are followed by the URL.
+// See https://gist.github.com/egmontkob/eb114294efbcd5adb1944c9f3cb5feda
+const (
+	linkOpen  = "\x1b]8;%s;%s\x1b\\"
+	linkClose = "\x1b]8;;\x1b\\"
+)
+
+// Xterm's modifyOtherKeys.  At level 2, keys that would otherwise be
+// ambiguous (or not reported at all) when modified are sent as
+// CSI 27 ; mods ; code ~.  Resetting restores the terminal's default.
+const (
+	otherKeysEnable = "\x1b[>4;2m"
+	otherKeysReset  = "\x1b[>4m"
+)
+
+// Window title and icon name.  XTerm can save these on a stack,
+// which we use to restore the original values on exit.
+const (
+	titleSet  = "\x1b]2;%s\a"
+	iconSet   = "\x1b]1;%s\a"
+	titlePush = "\x1b[22;0t"
+	titlePop  = "\x1b[23;0t"
+)
+
+// Cursor color.  The value is set as an XParseColor style string.
+const (
+	cursorColorSet   = "\x1b]12;#%06x\a"
+	cursorColorReset = "\x1b]112\a"
 )
 
 // NewTerminfoScreen returns a Screen that uses the stock TTY interface
@@ -62,15 +122,40 @@ const (
 // $COLUMNS environment variables can be set to the actual window size,
 // otherwise defaults taken from the terminal database are used.
 func NewTerminfoScreen() (Screen, error) {
-	ti, e := terminfo.LookupTerminfo(os.Getenv("TERM"))
+	return NewTerminfoScreenFromTty(nil, "")
+}
+
+// NewTerminfoScreenFromTty returns a Screen that does its input and
+// output using the supplied Tty, and uses the terminfo description for
+// the named terminal type.  This makes it possible to drive a screen
+// over something other than the controlling terminal, such as an SSH
+// channel or a pseudo-terminal.  If tty is nil, then the default
+// (see NewDevTty) is opened when the screen is initialized.  If the
+// terminal name is empty, then $TERM is used.
+//
+// The Tty is owned by the Screen once it is initialized, and will be
+// closed when the Screen is finalized.
+func NewTerminfoScreenFromTty(tty Tty, term string) (Screen, error) {
+	t, e := newTScreen(tty, term)
+	if e != nil {
+		return nil, e
+	}
+	return t, nil
+}
+
+func newTScreen(tty Tty, term string) (*tScreen, error) {
+	if term == "" {
+		term = os.Getenv("TERM")
+	}
+	ti, e := terminfo.LookupTerminfo(term)
 	if e != nil {
-		ti, e = loadDynamicTerminfo(os.Getenv("TERM"))
+		ti, e = loadDynamicTerminfo(term)
 		if e != nil {
 			return nil, e
 		}
 		terminfo.AddTerminfo(ti)
 	}
-	t := &tScreen{ti: ti}
+	t := &tScreen{ti: ti, tty: tty}
 
 	t.keyexist = make(map[Key]bool)
 	t.keycodes = make(map[string]*tKeyCode)
@@ -79,15 +164,80 @@ func NewTerminfoScreen() (Screen, error) {
 	}
 	t.prepareKeys()
 	t.buildAcsMap()
-	t.sigwinch = make(chan os.Signal, 10)
+	t.resizeq = make(chan struct{}, 1)
 	t.fallback = make(map[rune]string)
 	for k, v := range RuneFallbacks {
 		t.fallback[k] = v
 	}
 
+	t.cuu = t.ansiCap(ti.CursorUp, "\x1b[%p1%dA")
+	t.cud = t.ansiCap(ti.CursorDown, "\x1b[%p1%dB")
+	t.cuf = t.ansiCap(ti.CursorRight, "\x1b[%p1%dC")
+	t.cuu1 = t.ansiCap(ti.CursorUp1, "\x1b[A")
+	t.cuf1 = t.ansiCap(ti.CursorRight1, "\x1b[C")
+	t.cub1 = t.ansiCap(ti.CursorBack1, "\b")
+	t.cub = t.ansiCap(ti.CursorBack, "\x1b[%p1%dD")
+	t.cr = t.ansiCap(ti.CarriageRet, "\r")
+	// Output processing is disabled, so a line feed just moves down.
+	t.cud1 = t.ansiCap(ti.CursorDown1, "\n")
+	t.ed = t.ansiCap(ti.ClearEOS, "\x1b[J")
+	t.csr = t.ansiCap(ti.ScrollRegion, scrollRegionSet)
+	t.ind = t.ansiCap(ti.ScrollFwd, scrollFwd)
+	t.ri = t.ansiCap(ti.ScrollRev, scrollRev)
+	t.il = t.ansiCap(ti.InsertLines, insertLines)
+	t.dl = t.ansiCap(ti.DeleteLines, deleteLines)
+	t.el = t.ansiCap(ti.ClearEOL, clearEOL)
+	t.ech = t.ansiCap(ti.EraseChars, eraseChars)
+	t.rep = ti.RepeatChar
+	t.ich = t.ansiCap(ti.InsertChars, insertChars)
+	t.dch = t.ansiCap(ti.DeleteChars, deleteChars)
+	t.smir = ti.EnterInsert
+	t.rmir = ti.ExitInsert
+	t.bce = ti.BgColorErase
+	t.ss = t.ansiCap(ti.SetCursorStyle, "\x1b[%p1%d q")
+	t.se = ti.ResetCursor
+	if t.se == "" && t.ss != "" {
+		t.se = ti.TParm(t.ss, 0)
+	}
+
+	// Terminals that do not know about styled or colored underlines
+	// may misinterpret them, so they are only used if terminfo has
+	// them, unless the user says otherwise.
+	t.smulx = ti.UnderlineStyle
+	t.setulc = ti.UnderlineColor
+	switch os.Getenv("TCELL_UNDERLINE") {
+	case "disable":
+		t.smulx = ""
+		t.setulc = ""
+	case "enable":
+		t.smulx = t.ansiCap(t.smulx, underStyleSet)
+		t.setulc = t.ansiCap(t.setulc, underColorSet)
+	}
+
+	t.detectImages()
+	t.cellw = defCellWidth
+	t.cellh = defCellHeight
+
 	return t, nil
 }
 
+// ansiCap returns the given capability, unless it is missing and the
+// terminal appears to be an ANSI (ECMA-48) terminal, in which case the
+// standard sequence is returned instead.  Our built-in terminal database
+// only has a subset of capabilities, so this lets us use the others.
+func (t *tScreen) ansiCap(val, std string) string {
+	if val == "" && t.isAnsi() {
+		return std
+	}
+	return val
+}
+
+// isAnsi returns true if the terminal appears to understand ANSI
+// (ECMA-48) control sequences, based on its cursor addressing.
+func (t *tScreen) isAnsi() bool {
+	return strings.HasPrefix(t.ti.SetCursor, "\x1b[")
+}
+
 // tKeyCode represents a combination of a key code and modifiers.
 type tKeyCode struct {
 	key Key
@@ -96,48 +246,123 @@ type tKeyCode struct {
 
 // tScreen represents a screen backed by a terminfo implementation.
 type tScreen struct {
-	ti        *terminfo.Terminfo
-	h         int
-	w         int
-	fini      bool
-	cells     CellBuffer
-	in        io.Reader
-	out       io.Writer
-	buffering bool // true if we are collecting writes to buf instead of sending directly to out
-	buf       bytes.Buffer
-	escbuf    *bytes.Buffer
-	paste     bool
-	curstyle  Style
-	style     Style
-	evch      chan Event
-	sigwinch  chan os.Signal
-	quit      chan struct{}
-	indoneq   chan struct{}
-	keyexist  map[Key]bool
-	keycodes  map[string]*tKeyCode
-	keychan   chan []byte
-	keytimer  *time.Timer
-	keyexpire time.Time
-	cx        int
-	cy        int
-	mouse     []byte
-	clear     bool
-	cursorx   int
-	cursory   int
-	tiosp     *termiosPrivate
-	wasbtn    bool
-	acs       map[rune]string
-	charset   string
-	encoder   transform.Transformer
-	decoder   transform.Transformer
-	fallback  map[rune]string
-	colors    map[Color]Color
-	palette   []Color
-	truecolor bool
-	escaped   bool
-	buttondn  bool
-	rawseq    []string
-	finiOnce  sync.Once
+	ti         *terminfo.Terminfo
+	h          int
+	w          int
+	fini       bool
+	cells      CellBuffer
+	tty        Tty
+	buffering  bool // true if we are collecting writes to buf instead of sending directly to out
+	buf        bytes.Buffer
+	cellbuf    []byte              // reused to encode the contents of a cell
+	sgrbuf     []byte              // reused to build SGR sequences
+	encin      [utf8.UTFMax]byte   // reused to encode runes
+	encout     [16]byte            // reused for the encoder's output
+	isutf8     bool                // true if the encoding is UTF-8, which needs no transformation
+	parms      map[tParmKey]string // cache of evaluated parameterized strings
+	gotos      []string            // cache of cursor addressing, by position
+	frame      frameState
+	interval   time.Duration // least time between frames, if limited
+	lastdraw   time.Time     // when the last frame was drawn
+	drawpend   bool          // a frame is scheduled to be drawn
+	drawat     time.Time     // when the scheduled frame is to be drawn
+	drawtimer  *time.Timer
+	escbuf     *bytes.Buffer
+	paste      bool
+	curstyle   Style
+	style      Style
+	evch       chan Event
+	evpend     []Event // events waiting for room in evch
+	resizeq    chan struct{}
+	quit       chan struct{}
+	indoneq    chan struct{}
+	stopq      chan struct{}
+	inputdone  chan struct{}
+	held       [][]byte // input read by a stopped input loop
+	suspended  bool
+	keyexist   map[Key]bool
+	keycodes   map[string]*tKeyCode
+	keychan    chan []byte
+	keytimer   *time.Timer
+	keyexpire  time.Time
+	cx         int
+	cy         int
+	mouse      []byte
+	mouseon    bool
+	focuson    bool
+	kittykeys  KeyboardFlags
+	otherkeys  bool
+	syncout    bool // true if synchronized output is supported
+	syncfixed  bool // true if syncout was forced by the environment
+	clear      bool
+	cursorx    int
+	cursory    int
+	wasbtn     bool
+	acs        map[rune]string
+	charset    string
+	encoder    transform.Transformer
+	decoder    transform.Transformer
+	fallback   map[rune]string
+	colors     map[Color]Color
+	palette    []Color
+	palvals    []Color       // actual values of the palette colors
+	palset     map[int]Color // palette colors we have changed
+	palreplies int
+	truecolor  bool
+	escaped    bool
+	buttondn   bool
+	rawseq     []string
+	finiOnce   sync.Once
+	inline     int // number of lines used in inline mode, or 0
+	irow       int // row of the cursor within the inline region
+	cuu        string
+	cud        string
+	cuf        string
+	cuu1       string
+	cud1       string
+	cuf1       string
+	cub1       string
+	cub        string
+	cr         string
+	ed         string
+	csr        string
+	ind        string
+	ri         string
+	il         string
+	dl         string
+	scrolls    []tScroll // scrolls not yet sent to the terminal
+	el         string
+	ech        string
+	rep        string
+	bce        bool
+	ich        string
+	dch        string
+	smir       string
+	rmir       string
+	title      string
+	icon       string
+	hastitle   bool
+	hasicon    bool
+	titlesent  bool // true if the original title was pushed
+	ss         string
+	se         string
+	curshape   CursorStyle
+	curcolor   Color
+	smulx      string
+	setulc     string
+	url        string // URL of the open hyperlink, if any
+	urlid      string
+	images     []*tImage
+	imageid    int
+	imgproto   imageProtocol
+	imgfixed   bool // true if imgproto was set by the environment
+	cellw      int  // width of a cell in pixels
+	cellh      int
+	caps       Capabilities
+	probeq     chan struct{}
+	querymu    sync.Mutex // held while a query is outstanding
+	deffg      Color      // default colors, as reported
+	defbg      Color
 
 	sync.Mutex
 }
@@ -148,12 +373,15 @@ func (t *tScreen) Init() error {
 	t.keychan = make(chan []byte, 10)
 	t.rawseq = make([]string, 0, 4)
 	t.keytimer = time.NewTimer(time.Millisecond * 50)
+	t.drawtimer = time.NewTimer(time.Hour)
+	t.drawtimer.Stop()
 	t.charset = "UTF-8"
 
 	t.charset = getCharset()
 	if enc := GetEncoding(t.charset); enc != nil {
 		t.encoder = enc.NewEncoder()
 		t.decoder = enc.NewDecoder()
+		t.isutf8 = isUTF8(enc)
 	} else {
 		return ErrNoCharset
 	}
@@ -168,9 +396,22 @@ func (t *tScreen) Init() error {
 	if i, _ := strconv.Atoi(os.Getenv("COLUMNS")); i != 0 {
 		w = i
 	}
-	if e := t.termioInit(); e != nil {
+	if t.tty == nil {
+		tty, e := NewDevTty()
+		if e != nil {
+			return e
+		}
+		t.tty = tty
+	}
+	if e := t.tty.Start(); e != nil {
 		return e
 	}
+	t.tty.NotifyResize(func() {
+		select {
+		case t.resizeq <- struct{}{}:
+		default:
+		}
+	})
 
 	if t.ti.SetFgBgRGB != "" || t.ti.SetFgRGB != "" || t.ti.SetBgRGB != "" {
 		t.truecolor = true
@@ -180,19 +421,25 @@ func (t *tScreen) Init() error {
 	if os.Getenv("TCELL_TRUECOLOR") == "disable" {
 		t.truecolor = false
 	}
-	t.colors = make(map[Color]Color)
 	t.palette = make([]Color, t.nColors())
+	t.palvals = make([]Color, t.nColors())
 	for i := 0; i < t.nColors(); i++ {
 		t.palette[i] = Color(i) | ColorValid
-		// identity map for our builtin colors
-		t.colors[Color(i)|ColorValid] = Color(i) | ColorValid
+		t.palvals[i] = t.palette[i].TrueColor()
 	}
+	t.resetColorMap()
 
-	t.TPuts(ti.EnterCA)
-	t.TPuts(ti.HideCursor)
-	t.TPuts(ti.EnableAcs)
-	t.TPuts(ti.Clear)
-	t.TPuts(pasteEnable)
+	t.syncout = ti.Sync != ""
+	switch os.Getenv("TCELL_SYNCOUTPUT") {
+	case "disable":
+		t.syncout = false
+		t.syncfixed = true
+	case "enable":
+		t.syncout = true
+		t.syncfixed = true
+	}
+
+	t.engage()
 
 	t.quit = make(chan struct{})
 
@@ -206,12 +453,152 @@ func (t *tScreen) Init() error {
 	t.resize()
 	t.Unlock()
 
+	t.stopq = make(chan struct{})
+	t.inputdone = make(chan struct{})
 	go t.mainLoop()
-	go t.inputLoop()
+	go t.inputLoop(t.stopq, t.inputdone, nil)
+
+	t.probe()
 
 	return nil
 }
 
+// engage emits the sequences needed to take over the terminal for
+// full screen use.  It is used both by Init, and when resuming.
+func (t *tScreen) engage() {
+	ti := t.ti
+	if t.inline > 0 {
+		t.reserve()
+	} else {
+		t.TPuts(ti.EnterCA)
+	}
+	t.TPuts(ti.HideCursor)
+	t.TPuts(ti.EnableAcs)
+	if t.inline == 0 {
+		t.TPuts(ti.Clear)
+	}
+	t.sendTitle()
+	if t.curshape != CursorStyleDefault {
+		t.sendCursorStyle()
+	}
+	if t.curcolor != ColorDefault {
+		t.sendCursorColor()
+	}
+	t.sendPalette()
+	t.TPuts(pasteEnable)
+	if t.mouseon {
+		t.TPuts(ti.TParm(ti.MouseMode, 1))
+	}
+	if t.focuson {
+		t.TPuts(focusEnable)
+	}
+	if t.kittykeys != 0 && t.isAnsi() {
+		t.TPuts(fmt.Sprintf(kittyKeyPush, t.kittykeys))
+	}
+	if t.otherkeys && t.isAnsi() {
+		t.TPuts(otherKeysEnable)
+	}
+}
+
+// disengage undoes the effects of engage, restoring the terminal
+// for use by another program.
+func (t *tScreen) disengage() {
+	ti := t.ti
+	t.sendUrl("", "")
+	t.deleteImages()
+	t.TPuts(ti.ShowCursor)
+	t.TPuts(ti.AttrOff)
+	if t.titlesent {
+		t.TPuts(titlePop)
+		t.titlesent = false
+	}
+	if t.curshape != CursorStyleDefault {
+		t.TPuts(t.se)
+	}
+	if t.curcolor != ColorDefault && t.isAnsi() {
+		t.writeString(cursorColorReset)
+	}
+	t.resetPalette()
+	if t.inline > 0 {
+		t.release()
+	} else {
+		t.TPuts(ti.Clear)
+		t.TPuts(ti.ExitCA)
+	}
+	t.TPuts(ti.ExitKeypad)
+	t.TPuts(ti.TParm(ti.MouseMode, 0))
+	t.TPuts(pasteDisable)
+	if t.focuson {
+		t.TPuts(focusDisable)
+	}
+	if t.kittykeys != 0 && t.isAnsi() {
+		t.TPuts(kittyKeyPop)
+	}
+	if t.otherkeys && t.isAnsi() {
+		t.TPuts(otherKeysReset)
+	}
+	t.curstyle = styleInvalid
+}
+
+// inputStopTimeout is how long Suspend waits for the input loop to stop.
+const inputStopTimeout = time.Second
+
+func (t *tScreen) Suspend() error {
+	t.Lock()
+	if t.fini || t.suspended {
+		t.Unlock()
+		return nil
+	}
+	t.suspended = true
+	t.disengage()
+	t.Unlock()
+
+	// Stop the input loop, so that it does not steal input meant
+	// for whatever program takes over the terminal.
+	close(t.stopq)
+	e := t.tty.Stop()
+	select {
+	case <-t.inputdone:
+	case <-time.After(inputStopTimeout):
+		if e == nil {
+			e = ErrInputNotStopped
+		}
+	}
+	return e
+}
+
+func (t *tScreen) Resume() error {
+	t.Lock()
+	if t.fini || !t.suspended {
+		t.Unlock()
+		return nil
+	}
+	t.Unlock()
+
+	if e := t.tty.Start(); e != nil {
+		return e
+	}
+
+	t.Lock()
+	t.suspended = false
+	t.stopq = make(chan struct{})
+	t.inputdone = make(chan struct{})
+	go t.inputLoop(t.stopq, t.inputdone, t.held)
+	t.held = nil
+
+	t.engage()
+	t.cx = -1
+	t.cy = -1
+	t.resize()
+	t.clear = true
+	t.cells.Invalidate()
+	if !t.frame.deferDraw(frameShow) {
+		t.draw()
+	}
+	t.Unlock()
+	return nil
+}
+
 func (t *tScreen) SetPaste(p bool) {
 	t.paste = p
 }
@@ -240,48 +627,61 @@ func (t *tScreen) prepareKeyModReplace(key Key, replace Key, mod ModMask, val st
 	}
 }
 
-func (t *tScreen) prepareKeyModXTerm(key Key, val string) {
+// xtermReplaced gives, for the modifiers that have them, the offset to
+// the legacy key that a modified function key replaces.  (For example,
+// Shift-F1 was once reported as F13.)
+var xtermReplaced = map[ModMask]Key{
+	ModShift:           12,
+	ModAlt:             48,
+	ModAlt | ModShift:  60,
+	ModCtrl:            24,
+	ModCtrl | ModShift: 36,
+}
 
-	if strings.HasPrefix(val, "\x1b[") && strings.HasSuffix(val, "~") {
+// xtermModifiers decodes the modifier parameter of an xterm style key
+// sequence, which is one plus a bit mask of the modifiers.  Please see
+// https://invisible-island.net/xterm/ctlseqs/ctlseqs.pdf for more
+// information (specifically "PC-Style Function Keys").
+func xtermModifiers(n int) ModMask {
+	mod := ModNone
+	if n--; n > 0 {
+		if n&1 != 0 {
+			mod |= ModShift
+		}
+		if n&2 != 0 {
+			mod |= ModAlt
+		}
+		if n&4 != 0 {
+			mod |= ModCtrl
+		}
+		if n&8 != 0 {
+			mod |= ModMeta
+		}
+	}
+	return mod
+}
 
-		// Drop the trailing ~
-		val = val[:len(val)-1]
-
-		// These suffixes are calculated assuming Xterm style modifier suffixes.
-		// Please see https://invisible-island.net/xterm/ctlseqs/ctlseqs.pdf for
-		// more information (specifically "PC-Style Function Keys").
-		t.prepareKeyModReplace(key, key+12, ModShift, val+";2~")
-		t.prepareKeyModReplace(key, key+48, ModAlt, val+";3~")
-		t.prepareKeyModReplace(key, key+60, ModAlt|ModShift, val+";4~")
-		t.prepareKeyModReplace(key, key+24, ModCtrl, val+";5~")
-		t.prepareKeyModReplace(key, key+36, ModCtrl|ModShift, val+";6~")
-		t.prepareKeyMod(key, ModAlt|ModCtrl, val+";7~")
-		t.prepareKeyMod(key, ModShift|ModAlt|ModCtrl, val+";8~")
-		t.prepareKeyMod(key, ModMeta, val+";9~")
-		t.prepareKeyMod(key, ModMeta|ModShift, val+";10~")
-		t.prepareKeyMod(key, ModMeta|ModAlt, val+";11~")
-		t.prepareKeyMod(key, ModMeta|ModAlt|ModShift, val+";12~")
-		t.prepareKeyMod(key, ModMeta|ModCtrl, val+";13~")
-		t.prepareKeyMod(key, ModMeta|ModCtrl|ModShift, val+";14~")
-		t.prepareKeyMod(key, ModMeta|ModCtrl|ModAlt, val+";15~")
-		t.prepareKeyMod(key, ModMeta|ModCtrl|ModAlt|ModShift, val+";16~")
+func (t *tScreen) prepareKeyModXTerm(key Key, val string) {
+	var prefix, suffix string
+	if strings.HasPrefix(val, "\x1b[") && strings.HasSuffix(val, "~") {
+		// CSI code ; mods ~
+		prefix = val[:len(val)-1] + ";"
+		suffix = "~"
 	} else if strings.HasPrefix(val, "\x1bO") && len(val) == 3 {
-		val = val[2:]
-		t.prepareKeyModReplace(key, key+12, ModShift, "\x1b[1;2"+val)
-		t.prepareKeyModReplace(key, key+48, ModAlt, "\x1b[1;3"+val)
-		t.prepareKeyModReplace(key, key+24, ModCtrl, "\x1b[1;5"+val)
-		t.prepareKeyModReplace(key, key+36, ModCtrl|ModShift, "\x1b[1;6"+val)
-		t.prepareKeyModReplace(key, key+60, ModAlt|ModShift, "\x1b[1;4"+val)
-		t.prepareKeyMod(key, ModAlt|ModCtrl, "\x1b[1;7"+val)
-		t.prepareKeyMod(key, ModShift|ModAlt|ModCtrl, "\x1b[1;8"+val)
-		t.prepareKeyMod(key, ModMeta, "\x1b[1;9"+val)
-		t.prepareKeyMod(key, ModMeta|ModShift, "\x1b[1;10"+val)
-		t.prepareKeyMod(key, ModMeta|ModAlt, "\x1b[1;11"+val)
-		t.prepareKeyMod(key, ModMeta|ModAlt|ModShift, "\x1b[1;12"+val)
-		t.prepareKeyMod(key, ModMeta|ModCtrl, "\x1b[1;13"+val)
-		t.prepareKeyMod(key, ModMeta|ModCtrl|ModShift, "\x1b[1;14"+val)
-		t.prepareKeyMod(key, ModMeta|ModCtrl|ModAlt, "\x1b[1;15"+val)
-		t.prepareKeyMod(key, ModMeta|ModCtrl|ModAlt|ModShift, "\x1b[1;16"+val)
+		// CSI 1 ; mods final
+		prefix = "\x1b[1;"
+		suffix = val[2:]
+	} else {
+		return
+	}
+	for n := 2; n <= 16; n++ {
+		mod := xtermModifiers(n)
+		seq := prefix + strconv.Itoa(n) + suffix
+		if offset, ok := xtermReplaced[mod]; ok {
+			t.prepareKeyModReplace(key, key+offset, mod, seq)
+		} else {
+			t.prepareKeyMod(key, mod, seq)
+		}
 	}
 }
 
@@ -518,18 +918,13 @@ func (t *tScreen) finish() {
 	t.Lock()
 	defer t.Unlock()
 
-	ti := t.ti
 	t.cells.Resize(0, 0)
-	t.TPuts(ti.ShowCursor)
-	t.TPuts(ti.AttrOff)
-	t.TPuts(ti.Clear)
-	t.TPuts(ti.ExitCA)
-	t.TPuts(ti.ExitKeypad)
-	t.TPuts(ti.TParm(ti.MouseMode, 0))
-	t.TPuts(pasteDisable)
-	t.curstyle = styleInvalid
+	if !t.suspended {
+		t.disengage()
+	}
 	t.clear = false
 	t.fini = true
+	t.cancelShow()
 
 	select {
 	case <-t.quit:
@@ -539,7 +934,19 @@ func (t *tScreen) finish() {
 		close(t.quit)
 	}
 
-	t.termioFini()
+	t.tty.NotifyResize(nil)
+
+	// wait for the main loop to exit, releasing the lock, which it
+	// may be waiting for (to draw)
+	t.Unlock()
+	<-t.indoneq
+	t.Lock()
+
+	if !t.suspended {
+		close(t.stopq)
+		t.tty.Stop()
+	}
+	t.tty.Close()
 }
 
 func (t *tScreen) SetStyle(style Style) {
@@ -552,6 +959,7 @@ func (t *tScreen) SetStyle(style Style) {
 
 func (t *tScreen) Clear() {
 	t.Fill(' ', t.style)
+	t.ClearImages()
 }
 
 func (t *tScreen) Fill(r rune, style Style) {
@@ -570,6 +978,43 @@ func (t *tScreen) SetContent(x, y int, mainc rune, combc []rune, style Style) {
 	t.Unlock()
 }
 
+func (t *tScreen) SetWidthPolicy(policy WidthPolicy) {
+	t.Lock()
+	t.cells.SetWidthPolicy(policy)
+	t.Unlock()
+}
+
+func (t *tScreen) WidthPolicy() WidthPolicy {
+	t.Lock()
+	defer t.Unlock()
+	return t.cells.WidthPolicy()
+}
+
+func (t *tScreen) SetContentString(x, y int, cluster string, style Style) {
+	t.Lock()
+	if !t.fini {
+		t.cells.SetContentString(x, y, cluster, style)
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) SetContents(x, y int, cells []Cell) {
+	t.Lock()
+	if !t.fini {
+		t.cells.SetContents(x, y, cells)
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) PutString(x, y int, str string, style Style) int {
+	t.Lock()
+	defer t.Unlock()
+	if t.fini {
+		return 0
+	}
+	return t.cells.PutString(x, y, str, style)
+}
+
 func (t *tScreen) GetContent(x, y int) (rune, []rune, Style, int) {
 	t.Lock()
 	mainc, combc, style, width := t.cells.GetContent(x, y)
@@ -587,23 +1032,25 @@ func (t *tScreen) SetCell(x, y int, style Style, ch ...rune) {
 
 func (t *tScreen) encodeRune(r rune, buf []byte) []byte {
 
-	nb := make([]byte, 6)
-	ob := make([]byte, 6)
-	num := utf8.EncodeRune(ob, r)
-	ob = ob[:num]
+	num := utf8.EncodeRune(t.encin[:], r)
+	if t.isutf8 {
+		// Every rune can be displayed as it is.
+		return append(buf, t.encin[:num]...)
+	}
+	nb := t.encout[:]
 	dst := 0
 	var err error
 	if enc := t.encoder; enc != nil {
 		enc.Reset()
-		dst, _, err = enc.Transform(nb, ob, true)
+		dst, _, err = enc.Transform(nb, t.encin[:num], true)
 	}
 	if err != nil || dst == 0 || nb[0] == '\x1a' {
 		// Combining characters are elided
 		if len(buf) == 0 {
 			if acs, ok := t.acs[r]; ok {
-				buf = append(buf, []byte(acs)...)
+				buf = append(buf, acs...)
 			} else if fb, ok := t.fallback[r]; ok {
-				buf = append(buf, []byte(fb)...)
+				buf = append(buf, fb...)
 			} else {
 				buf = append(buf, '?')
 			}
@@ -627,7 +1074,7 @@ func (t *tScreen) sendFgBg(fg Color, bg Color) {
 		if ti.SetFgBgRGB != "" && fg.IsRGB() && bg.IsRGB() {
 			r1, g1, b1 := fg.RGB()
 			r2, g2, b2 := bg.RGB()
-			t.TPuts(ti.TParm(ti.SetFgBgRGB,
+			t.TPuts(t.tparm(ti.SetFgBgRGB,
 				int(r1), int(g1), int(b1),
 				int(r2), int(g2), int(b2)))
 			return
@@ -635,53 +1082,242 @@ func (t *tScreen) sendFgBg(fg Color, bg Color) {
 
 		if fg.IsRGB() && ti.SetFgRGB != "" {
 			r, g, b := fg.RGB()
-			t.TPuts(ti.TParm(ti.SetFgRGB, int(r), int(g), int(b)))
+			t.TPuts(t.tparm(ti.SetFgRGB, int(r), int(g), int(b)))
 			fg = ColorDefault
 		}
 
 		if bg.IsRGB() && ti.SetBgRGB != "" {
 			r, g, b := bg.RGB()
-			t.TPuts(ti.TParm(ti.SetBgRGB,
+			t.TPuts(t.tparm(ti.SetBgRGB,
 				int(r), int(g), int(b)))
 			bg = ColorDefault
 		}
 	}
 
 	if fg.Valid() {
-		if v, ok := t.colors[fg]; ok {
-			fg = v
-		} else {
-			v = FindColor(fg, t.palette)
-			t.colors[fg] = v
-			fg = v
-		}
+		fg = t.mapColor(fg)
 	}
 
 	if bg.Valid() {
-		if v, ok := t.colors[bg]; ok {
-			bg = v
-		} else {
-			v = FindColor(bg, t.palette)
-			t.colors[bg] = v
-			bg = v
-		}
+		bg = t.mapColor(bg)
 	}
 
 	if fg.Valid() && bg.Valid() && ti.SetFgBg != "" {
-		t.TPuts(ti.TParm(ti.SetFgBg, int(fg&0xff), int(bg&0xff)))
+		t.TPuts(t.tparm(ti.SetFgBg, int(fg&0xff), int(bg&0xff)))
 	} else {
 		if fg.Valid() && ti.SetFg != "" {
-			t.TPuts(ti.TParm(ti.SetFg, int(fg&0xff)))
+			t.TPuts(t.tparm(ti.SetFg, int(fg&0xff)))
 		}
 		if bg.Valid() && ti.SetBg != "" {
-			t.TPuts(ti.TParm(ti.SetBg, int(bg&0xff)))
+			t.TPuts(t.tparm(ti.SetBg, int(bg&0xff)))
 		}
 	}
 }
 
-func (t *tScreen) drawCell(x, y int) int {
+// sendUnderlineColor sets the color of the underline, if the terminal
+// supports it.  Only terminals using ANSI sequences can be given a
+// palette color.
+func (t *tScreen) sendUnderlineColor(c Color) {
+	if t.setulc == "" || !c.Valid() || t.ti.Colors == 0 {
+		return
+	}
+	if c.IsRGB() && t.truecolor {
+		t.TPuts(t.tparm(t.setulc, int(c.Hex())))
+		return
+	}
+	if !t.isAnsi() {
+		return
+	}
+	c = t.mapColor(c)
+	t.TPuts(fmt.Sprintf(underColorIndex, c&0xff))
+}
+
+// sendUrl opens a hyperlink to the given URL, closing any link that is
+// already open.  An empty URL just closes the open link.
+func (t *tScreen) sendUrl(url, id string) {
+	if url == "" {
+		id = ""
+	}
+	if url == t.url && id == t.urlid {
+		return
+	}
+	if !t.isAnsi() {
+		return
+	}
+	if url == "" {
+		t.writeString(linkClose)
+	} else {
+		params := ""
+		if id != "" {
+			// Parameters are separated by colons.
+			params = "id=" + strings.NewReplacer(":", "", ";", "").Replace(stripControls(id))
+		}
+		t.writeString(fmt.Sprintf(linkOpen, params, stripControls(url)))
+	}
+	t.url = url
+	t.urlid = id
+}
 
+// sendStyle sets the attributes and colors of the terminal for the style,
+// if they are not already set.  Where we can, only what has changed is
+// sent, rather than resetting everything and starting over.
+func (t *tScreen) sendStyle(style Style) {
+	if style == t.curstyle {
+		return
+	}
+	if t.curstyle == styleInvalid || !t.isAnsi() || !t.sendStyleChanges(style) {
+		fg, bg, attrs := style.Decompose()
+		t.TPuts(t.ti.AttrOff)
+		t.sendFgBg(fg, bg)
+		t.sendAttrs(attrs, style)
+		if attrs&AttrUnderline != 0 {
+			_, uc := style.DecomposeUnderline()
+			t.sendUnderlineColor(uc)
+		}
+	}
+	t.curstyle = style
+}
+
+// sendStyleChanges changes the terminal from the current style to the
+// new one, by turning off the attributes and colors that are no longer
+// wanted (using the standard SGR parameters), and sending the rest of
+// what has changed.  It returns false, having sent nothing, if resetting
+// everything would be shorter.
+func (t *tScreen) sendStyleChanges(style Style) bool {
 	ti := t.ti
+	ofg, obg, oattrs := t.curstyle.Decompose()
+	ous, ouc := t.curstyle.DecomposeUnderline()
+	fg, bg, attrs := style.Decompose()
+	us, uc := style.DecomposeUnderline()
+
+	var offbuf [10]string
+	off := offbuf[:0]
+	added := attrs &^ oattrs
+	if removed := oattrs &^ attrs; removed != 0 {
+		if removed&(AttrBold|AttrDim) != 0 {
+			// These are turned off together.
+			off = append(off, "22")
+			added |= attrs & (AttrBold | AttrDim)
+		}
+		if removed&AttrItalic != 0 {
+			off = append(off, "23")
+		}
+		if removed&AttrUnderline != 0 {
+			off = append(off, "24")
+		}
+		if removed&AttrBlink != 0 {
+			off = append(off, "25")
+		}
+		if removed&AttrReverse != 0 {
+			off = append(off, "27")
+		}
+		if removed&AttrStrikeThrough != 0 {
+			off = append(off, "29")
+		}
+	}
+	if attrs&AttrUnderline != 0 && us != ous {
+		added |= AttrUnderline
+	}
+	fgSet, bgSet, ucSet := ColorDefault, ColorDefault, ColorDefault
+	kept := len(ti.AttrOff) // what we would send to keep what is set
+	if fg.Valid() && fg != ofg {
+		fgSet = fg
+	} else if fg.Valid() {
+		kept += 5
+	} else if ofg.Valid() && ti.Colors > 0 {
+		off = append(off, "39")
+	}
+	if bg.Valid() && bg != obg {
+		bgSet = bg
+	} else if bg.Valid() {
+		kept += 5
+	} else if obg.Valid() && ti.Colors > 0 {
+		off = append(off, "49")
+	}
+	if t.setulc != "" && attrs&AttrUnderline != 0 {
+		if uc.Valid() && uc != ouc {
+			ucSet = uc
+		} else if uc.Valid() {
+			kept += 5
+		} else if ouc.Valid() {
+			off = append(off, "59")
+		}
+	}
+	for _, a := range []struct {
+		attr AttrMask
+		seq  string
+	}{
+		{AttrBold, ti.Bold},
+		{AttrUnderline, ti.Underline},
+		{AttrReverse, ti.Reverse},
+		{AttrBlink, ti.Blink},
+		{AttrDim, ti.Dim},
+		{AttrItalic, ti.Italic},
+		{AttrStrikeThrough, ti.StrikeThrough},
+	} {
+		if attrs&a.attr != 0 && added&a.attr == 0 {
+			kept += len(a.seq)
+		}
+	}
+
+	if len(off) > 0 {
+		seq := append(t.sgrbuf[:0], "\x1b["...)
+		for i, p := range off {
+			if i > 0 {
+				seq = append(seq, ';')
+			}
+			seq = append(seq, p...)
+		}
+		seq = append(seq, 'm')
+		t.sgrbuf = seq
+		if len(seq) > kept {
+			return false
+		}
+		t.writeBytes(seq)
+	}
+	if fgSet.Valid() || bgSet.Valid() {
+		t.sendFgBg(fgSet, bgSet)
+	}
+	t.sendAttrs(added, style)
+	if ucSet.Valid() {
+		t.sendUnderlineColor(ucSet)
+	}
+	return true
+}
+
+// sendAttrs turns on the attributes.  The underline style is that of the
+// style given.
+func (t *tScreen) sendAttrs(attrs AttrMask, style Style) {
+	ti := t.ti
+	if attrs&AttrBold != 0 {
+		t.TPuts(ti.Bold)
+	}
+	if attrs&AttrUnderline != 0 {
+		us, _ := style.DecomposeUnderline()
+		if us != UnderlineStyleSolid && t.smulx != "" {
+			t.TPuts(t.tparm(t.smulx, int(us)))
+		} else {
+			t.TPuts(ti.Underline)
+		}
+	}
+	if attrs&AttrReverse != 0 {
+		t.TPuts(ti.Reverse)
+	}
+	if attrs&AttrBlink != 0 {
+		t.TPuts(ti.Blink)
+	}
+	if attrs&AttrDim != 0 {
+		t.TPuts(ti.Dim)
+	}
+	if attrs&AttrItalic != 0 {
+		t.TPuts(ti.Italic)
+	}
+	if attrs&AttrStrikeThrough != 0 {
+		t.TPuts(ti.StrikeThrough)
+	}
+}
+
+func (t *tScreen) drawCell(x, y int) int {
 
 	mainc, combc, style, width := t.cells.GetContent(x, y)
 	if !t.cells.Dirty(x, y) {
@@ -689,7 +1325,7 @@ func (t *tScreen) drawCell(x, y int) int {
 	}
 
 	if t.cy != y || t.cx != x {
-		t.TPuts(ti.TGoto(x, y))
+		t.goTo(x, y)
 		t.cx = x
 		t.cy = y
 	}
@@ -697,35 +1333,9 @@ func (t *tScreen) drawCell(x, y int) int {
 	if style == StyleDefault {
 		style = t.style
 	}
-	if style != t.curstyle {
-		fg, bg, attrs := style.Decompose()
-
-		t.TPuts(ti.AttrOff)
+	t.sendStyle(style)
+	t.sendUrl(style.DecomposeUrl())
 
-		t.sendFgBg(fg, bg)
-		if attrs&AttrBold != 0 {
-			t.TPuts(ti.Bold)
-		}
-		if attrs&AttrUnderline != 0 {
-			t.TPuts(ti.Underline)
-		}
-		if attrs&AttrReverse != 0 {
-			t.TPuts(ti.Reverse)
-		}
-		if attrs&AttrBlink != 0 {
-			t.TPuts(ti.Blink)
-		}
-		if attrs&AttrDim != 0 {
-			t.TPuts(ti.Dim)
-		}
-		if attrs&AttrItalic != 0 {
-			t.TPuts(ti.Italic)
-		}
-		if attrs&AttrStrikeThrough != 0 {
-			t.TPuts(ti.StrikeThrough)
-		}
-		t.curstyle = style
-	}
 	// now emit runes - taking care to not overrun width with a
 	// wide character, and to ensure that we emit exactly one regular
 	// character followed up by any residual combing characters
@@ -734,19 +1344,15 @@ func (t *tScreen) drawCell(x, y int) int {
 		width = 1
 	}
 
-	var str string
-
-	buf := make([]byte, 0, 6)
-
-	buf = t.encodeRune(mainc, buf)
+	buf := t.encodeRune(mainc, t.cellbuf[:0])
 	for _, r := range combc {
 		buf = t.encodeRune(r, buf)
 	}
+	t.cellbuf = buf
 
-	str = string(buf)
-	if width > 1 && str == "?" {
+	if width > 1 && len(buf) == 1 && buf[0] == '?' {
 		// No FullWidth character support
-		str = "? "
+		buf = append(buf, ' ')
 		t.cx = -1
 	}
 
@@ -755,9 +1361,9 @@ func (t *tScreen) drawCell(x, y int) int {
 	if x > t.w-width {
 		// too wide to fit; emit a single space instead
 		width = 1
-		str = " "
+		buf = append(buf[:0], ' ')
 	}
-	t.writeString(str)
+	t.writeBytes(buf)
 	t.cx += width
 	t.cells.SetDirty(x, y, false)
 	if width > 1 {
@@ -778,6 +1384,43 @@ func (t *tScreen) HideCursor() {
 	t.ShowCursor(-1, -1)
 }
 
+func (t *tScreen) SetCursorStyle(cs CursorStyle) {
+	t.Lock()
+	t.curshape = cs
+	if !t.fini && !t.suspended {
+		t.sendCursorStyle()
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) SetCursorColor(c Color) {
+	t.Lock()
+	t.curcolor = c
+	if !t.fini && !t.suspended {
+		t.sendCursorColor()
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) sendCursorStyle() {
+	if t.curshape == CursorStyleDefault {
+		t.TPuts(t.se)
+	} else if t.ss != "" {
+		t.TPuts(t.ti.TParm(t.ss, int(t.curshape)))
+	}
+}
+
+func (t *tScreen) sendCursorColor() {
+	if !t.isAnsi() {
+		return
+	}
+	if v := t.curcolor.Hex(); v >= 0 {
+		t.writeString(fmt.Sprintf(cursorColorSet, v))
+	} else {
+		t.writeString(cursorColorReset)
+	}
+}
+
 func (t *tScreen) showCursor() {
 
 	x, y := t.cursorx, t.cursory
@@ -786,7 +1429,7 @@ func (t *tScreen) showCursor() {
 		t.hideCursor()
 		return
 	}
-	t.TPuts(t.ti.TGoto(x, y))
+	t.goTo(x, y)
 	t.TPuts(t.ti.ShowCursor)
 	t.cx = x
 	t.cy = y
@@ -802,7 +1445,16 @@ func (t *tScreen) writeString(s string) {
 	if t.buffering {
 		io.WriteString(&t.buf, s)
 	} else {
-		io.WriteString(t.out, s)
+		io.WriteString(t.tty, s)
+	}
+}
+
+// writeBytes is like writeString, but for a byte slice.
+func (t *tScreen) writeBytes(b []byte) {
+	if t.buffering {
+		t.buf.Write(b)
+	} else {
+		t.tty.Write(b)
 	}
 }
 
@@ -810,35 +1462,122 @@ func (t *tScreen) TPuts(s string) {
 	if t.buffering {
 		t.ti.TPuts(&t.buf, s)
 	} else {
-		t.ti.TPuts(t.out, s)
+		t.ti.TPuts(t.tty, s)
 	}
 }
 
+// maxParms is the number of evaluated parameterized strings we keep.
+// Only a few colors and motions are usually in use, so this is plenty.
+const maxParms = 1024
+
+// tParmKey identifies a parameterized string with its parameters.  A
+// count of -1 is used for a string that is simply repeated p[0] times.
+type tParmKey struct {
+	s string
+	n int
+	p [6]int
+}
+
+// tparm is like TParm, but the result is cached, as the same sequences
+// (colors, and cursor motions in particular) are used over and over,
+// and evaluating them each time costs both time and allocations.
+func (t *tScreen) tparm(s string, p ...int) string {
+	key := tParmKey{s: s, n: len(p)}
+	if len(p) > len(key.p) {
+		return t.ti.TParm(s, p...)
+	}
+	copy(key.p[:], p)
+	return t.cacheParm(key, func() string { return t.ti.TParm(s, p...) })
+}
+
+// tgoto is like TGoto, but the result is cached.  The cursor may be
+// moved anywhere on the screen, so positions are cached apart from the
+// other sequences, in a table with room for them all.
+func (t *tScreen) tgoto(x, y int) string {
+	if x < 0 || y < 0 || x >= t.w || y >= t.h {
+		return t.tparm(t.ti.SetCursor, y, x)
+	}
+	if len(t.gotos) != t.w*t.h {
+		t.gotos = make([]string, t.w*t.h)
+	}
+	seq := &t.gotos[y*t.w+x]
+	if *seq == "" {
+		*seq = t.ti.TGoto(x, y)
+	}
+	return *seq
+}
+
+// repeat returns the string repeated n times, with the result cached.
+func (t *tScreen) repeat(s string, n int) string {
+	key := tParmKey{s: s, n: -1}
+	key.p[0] = n
+	return t.cacheParm(key, func() string { return strings.Repeat(s, n) })
+}
+
+func (t *tScreen) cacheParm(key tParmKey, eval func() string) string {
+	if seq, ok := t.parms[key]; ok {
+		return seq
+	}
+	if t.parms == nil || len(t.parms) >= maxParms {
+		t.parms = make(map[tParmKey]string)
+	}
+	seq := eval()
+	t.parms[key] = seq
+	return seq
+}
+
 func (t *tScreen) Show() {
 	t.Lock()
-	if !t.fini {
+	if !t.frame.deferDraw(frameShow) {
+		t.scheduleShow()
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) show() {
+	if !t.fini && !t.suspended {
 		t.resize()
 		t.draw()
 	}
-	t.Unlock()
+}
+
+// redraw draws every cell, after the terminal is resized.
+func (t *tScreen) redraw() {
+	if !t.fini && !t.suspended {
+		t.cx = -1
+		t.cy = -1
+		t.resize()
+		t.cells.Invalidate()
+		t.draw()
+	}
 }
 
 func (t *tScreen) clearScreen() {
 	fg, bg, _ := t.style.Decompose()
 	t.sendFgBg(fg, bg)
-	t.TPuts(t.ti.Clear)
+	if t.inline > 0 {
+		t.goTo(0, 0)
+		t.TPuts(t.ed)
+	} else {
+		t.TPuts(t.ti.Clear)
+	}
 	t.clear = false
+	t.scrolls = nil
+	t.curstyle = styleInvalid
+	for _, im := range t.images {
+		im.shown = false
+	}
 }
 
 func (t *tScreen) hideCursor() {
 	// does not update cursor position
 	if t.ti.HideCursor != "" {
 		t.TPuts(t.ti.HideCursor)
-	} else {
+	} else if t.inline == 0 {
 		// No way to hide cursor, stick it
 		// at bottom right of screen
 		t.cx, t.cy = t.cells.Size()
-		t.TPuts(t.ti.TGoto(t.cx, t.cy))
+		t.TPuts(t.tgoto(t.cx, t.cy))
 	}
 }
 
@@ -853,15 +1592,38 @@ func (t *tScreen) draw() {
 		t.buffering = false
 	}()
 
+	if t.syncout {
+		if t.ti.Sync != "" {
+			t.TPuts(t.tparm(t.ti.Sync, 1))
+		} else {
+			t.TPuts(syncBegin)
+		}
+	}
+
 	// hide the cursor while we move stuff around
 	t.hideCursor()
 
 	if t.clear {
 		t.clearScreen()
 	}
+	t.sendScrolls()
 
 	for y := 0; y < t.h; y++ {
+		t.shiftRow(y)
+		plain := 0 // cells before this are not worth drawing as a run
 		for x := 0; x < t.w; x++ {
+			if len(t.images) > 0 && t.imageCovers(x, y) {
+				t.cells.SetDirty(x, y, false)
+				continue
+			}
+			if x >= plain {
+				n, end := t.drawRun(x, y)
+				if n > 0 {
+					x += n - 1
+					continue
+				}
+				plain = end
+			}
 			width := t.drawCell(x, y)
 			if width > 1 {
 				if x+1 < t.w {
@@ -875,22 +1637,103 @@ func (t *tScreen) draw() {
 		}
 	}
 
+	// close any link, so that it does not apply to what else might
+	// be written at the cursor
+	t.sendUrl("", "")
+
+	t.drawImages()
+
 	// restore the cursor
 	t.showCursor()
 
-	t.buf.WriteTo(t.out)
+	if t.syncout {
+		if t.ti.Sync != "" {
+			t.TPuts(t.tparm(t.ti.Sync, 2))
+		} else {
+			t.TPuts(syncEnd)
+		}
+	}
+
+	t.buf.WriteTo(t.tty)
+	if t.interval != 0 {
+		t.lastdraw = time.Now()
+	}
 }
 
 func (t *tScreen) EnableMouse() {
+	t.Lock()
 	if len(t.mouse) != 0 {
-		t.TPuts(t.ti.TParm(t.ti.MouseMode, 1))
+		t.mouseon = true
+		if !t.fini && !t.suspended {
+			t.TPuts(t.ti.TParm(t.ti.MouseMode, 1))
+		}
 	}
+	t.Unlock()
 }
 
 func (t *tScreen) DisableMouse() {
+	t.Lock()
 	if len(t.mouse) != 0 {
-		t.TPuts(t.ti.TParm(t.ti.MouseMode, 0))
+		t.mouseon = false
+		if !t.fini && !t.suspended {
+			t.TPuts(t.ti.TParm(t.ti.MouseMode, 0))
+		}
 	}
+	t.Unlock()
+}
+
+func (t *tScreen) EnableFocus() {
+	t.Lock()
+	t.focuson = true
+	if !t.fini && !t.suspended {
+		t.TPuts(focusEnable)
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) DisableFocus() {
+	t.Lock()
+	t.focuson = false
+	if !t.fini && !t.suspended {
+		t.TPuts(focusDisable)
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) EnableKittyKeyboard(flags KeyboardFlags) {
+	t.Lock()
+	if !t.fini && !t.suspended && t.isAnsi() {
+		if t.kittykeys != 0 {
+			t.TPuts(kittyKeyPop)
+		}
+		if flags != 0 {
+			t.TPuts(fmt.Sprintf(kittyKeyPush, flags))
+		}
+	}
+	t.kittykeys = flags
+	t.Unlock()
+}
+
+func (t *tScreen) DisableKittyKeyboard() {
+	t.EnableKittyKeyboard(0)
+}
+
+func (t *tScreen) EnableModifyOtherKeys() {
+	t.Lock()
+	t.otherkeys = true
+	if !t.fini && !t.suspended && t.isAnsi() {
+		t.TPuts(otherKeysEnable)
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) DisableModifyOtherKeys() {
+	t.Lock()
+	if t.otherkeys && !t.fini && !t.suspended && t.isAnsi() {
+		t.TPuts(otherKeysReset)
+	}
+	t.otherkeys = false
+	t.Unlock()
 }
 
 func (t *tScreen) Size() (int, int) {
@@ -902,12 +1745,20 @@ func (t *tScreen) Size() (int, int) {
 
 func (t *tScreen) resize() {
 	if w, h, e := t.getWinSize(); e == nil {
+		if t.inline > 0 {
+			h = t.inlineHeight(h)
+			if t.irow >= h {
+				t.irow = h - 1
+			}
+		}
 		if w != t.w || h != t.h {
 			t.cx = -1
 			t.cy = -1
 
 			t.cells.Resize(w, h)
 			t.cells.Invalidate()
+			t.scrolls = nil
+			t.gotos = nil
 			t.h = h
 			t.w = w
 			ev := NewEventResize(w, h)
@@ -916,6 +1767,29 @@ func (t *tScreen) resize() {
 	}
 }
 
+// getWinSize returns the window size reported by the tty, falling back
+// to $COLUMNS and $LINES, and then the terminal database, for any
+// dimension that is not known.
+func (t *tScreen) getWinSize() (int, int, error) {
+	w, h, e := t.tty.WindowSize()
+	if e != nil {
+		return -1, -1, e
+	}
+	if w == 0 {
+		w, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if w == 0 {
+		w = t.ti.Columns
+	}
+	if h == 0 {
+		h, _ = strconv.Atoi(os.Getenv("LINES"))
+	}
+	if h == 0 {
+		h = t.ti.Lines
+	}
+	return w, h, nil
+}
+
 func (t *tScreen) Colors() int {
 	// this doesn't change, no need for lock
 	if t.truecolor {
@@ -1004,6 +1878,71 @@ func (t *tScreen) buildAcsMap() {
 	}
 }
 
+// kittyKeys maps the key codes used by the kitty keyboard protocol (in
+// sequences ending in 'u') for keys that are not reported as text.  Most
+// of these are code points in the private use area.
+var kittyKeys = map[int]Key{
+	9:     KeyTab,
+	13:    KeyEnter,
+	27:    KeyEsc,
+	127:   KeyBackspace2,
+	57361: KeyPrint,
+	57362: KeyPause,
+	57414: KeyEnter,
+	57417: KeyLeft,
+	57418: KeyRight,
+	57419: KeyUp,
+	57420: KeyDown,
+	57421: KeyPgUp,
+	57422: KeyPgDn,
+	57423: KeyHome,
+	57424: KeyEnd,
+	57425: KeyInsert,
+	57426: KeyDelete,
+	57427: KeyCenter,
+}
+
+// kittyKeypad is the text for the keypad keys, starting with KP_0 (57399).
+// KP_Enter (57414) is in kittyKeys instead.
+const kittyKeypad = "0123456789./*-+\n=,"
+
+// kittyTildeKeys are the keys reported in sequences ending in '~'.
+var kittyTildeKeys = map[int]Key{
+	2:  KeyInsert,
+	3:  KeyDelete,
+	5:  KeyPgUp,
+	6:  KeyPgDn,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// kittyLetterKeys are the keys reported with a final letter.  (F3 uses
+// 13~ instead of R, which would be confused with a cursor position.)
+var kittyLetterKeys = map[byte]Key{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'E': KeyCenter,
+	'F': KeyEnd,
+	'H': KeyHome,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'S': KeyF4,
+}
+
 func (t *tScreen) PostEventWait(ev Event) {
 	t.evch <- ev
 }
@@ -1280,6 +2219,291 @@ func (t *tScreen) parseXtermMouse(buf *bytes.Buffer, evs *[]Event) (bool, bool)
 	return true, false
 }
 
+// parseFocus is like parseSgrMouse, but it parses a focus in (CSI I)
+// or focus out (CSI O) report.
+func (t *tScreen) parseFocus(buf *bytes.Buffer, evs *[]Event) (bool, bool) {
+	b := buf.Bytes()
+
+	state := 0
+	if t.escaped {
+		state = 1
+	}
+
+	for i := range b {
+		switch state {
+		case 0:
+			switch b[i] {
+			case '\x1b':
+				state = 1
+			case '\x9b':
+				state = 2
+			default:
+				return false, false
+			}
+		case 1:
+			if b[i] != '[' {
+				return false, false
+			}
+			state = 2
+		case 2:
+			if b[i] != 'I' && b[i] != 'O' {
+				return false, false
+			}
+			focused := b[i] == 'I'
+			for i >= 0 {
+				by, _ := buf.ReadByte()
+				t.escbuf.WriteByte(by)
+				i--
+			}
+			t.escaped = false
+			*evs = append(*evs, NewEventFocus(focused, t.escbuf.String()))
+			t.escbuf.Reset()
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// parseCellSize is like parseSgrMouse, but it parses the terminal's
+// report of the size of a cell in pixels (CSI 6 ; height ; width t),
+// which is needed to scale sixel images.
+func (t *tScreen) parseCellSize(buf *bytes.Buffer) (bool, bool) {
+	b := buf.Bytes()
+
+	var p [3]int
+	field := 0
+	state := 0
+	if t.escaped {
+		state = 1
+	}
+
+	for i := range b {
+		switch state {
+		case 0:
+			switch b[i] {
+			case '\x1b':
+				state = 1
+			case '\x9b':
+				state = 2
+			default:
+				return false, false
+			}
+		case 1:
+			if b[i] != '[' {
+				return false, false
+			}
+			state = 2
+		case 2:
+			switch {
+			case b[i] >= '0' && b[i] <= '9':
+				p[field] = p[field]*10 + int(b[i]-'0')
+			case b[i] == ';' && field < 2:
+				field++
+			case b[i] == 't' && field == 2 && p[0] == 6:
+				buf.Next(i + 1)
+				t.escbuf.Reset()
+				t.escaped = false
+				if p[1] > 0 && p[2] > 0 {
+					t.cellh = p[1]
+					t.cellw = p[2]
+				}
+				return true, true
+			default:
+				return false, false
+			}
+		}
+	}
+	return true, false
+}
+
+// parseExtendedKey is like parseSgrMouse, but it parses a key reported
+// with the kitty keyboard protocol, or with xterm's modifyOtherKeys.
+// The former have the form CSI code[:shifted[:base]] ; mods[:type] ;
+// text final, and the latter CSI 27 ; mods ; code ~ (or, if the
+// formatOtherKeys resource is set, CSI code ; mods u).  Sequences that
+// end in 'u' are always recognized, as are those of modifyOtherKeys,
+// but other kitty sequences ending in '~' or a letter are only handled
+// here while the protocol is enabled, since otherwise they are legacy
+// keys.  The terminal's reply to a query of the current kitty flags
+// (CSI ? flags u) is consumed silently.
+func (t *tScreen) parseExtendedKey(buf *bytes.Buffer, evs *[]Event) (bool, bool) {
+	b := buf.Bytes()
+
+	var p [3][3]int
+	field, sub := 0, 0
+	reply := false
+	state := 0
+	if t.escaped {
+		state = 1
+	}
+
+	for i := range b {
+		switch state {
+		case 0:
+			switch b[i] {
+			case '\x1b':
+				state = 1
+			case '\x9b':
+				state = 2
+			default:
+				return false, false
+			}
+		case 1:
+			if b[i] != '[' {
+				return false, false
+			}
+			state = 2
+		case 2:
+			if b[i] == '?' {
+				reply = true
+				state = 3
+				continue
+			}
+			state = 3
+			fallthrough
+		case 3:
+			c := b[i]
+			switch {
+			case c >= '0' && c <= '9':
+				if field < 3 && sub < 3 {
+					p[field][sub] = p[field][sub]*10 + int(c-'0')
+				}
+			case c == ':':
+				sub++
+			case c == ';':
+				field++
+				sub = 0
+			case c == 'u',
+				c == '~' && p[0][0] == 27 && field == 2 && !reply,
+				(c == '~' || kittyLetterKeys[c] != 0) && t.kittykeys != 0 && !reply:
+				for i >= 0 {
+					by, _ := buf.ReadByte()
+					t.escbuf.WriteByte(by)
+					i--
+				}
+				t.escaped = false
+				if reply {
+					t.caps.KittyKeyboard = true
+				} else if ev := t.buildKittyKey(c, p); ev != nil {
+					*evs = append(*evs, ev)
+				}
+				t.escbuf.Reset()
+				return true, true
+			default:
+				return false, false
+			}
+		}
+	}
+	return true, false
+}
+
+// buildKittyKey returns the event for a kitty protocol (or xterm
+// modifyOtherKeys) key report, or nil if the key is one we do not
+// report (such as modifier keys).
+func (t *tScreen) buildKittyKey(final byte, p [3][3]int) *EventKey {
+	key := KeyRune
+	var ch rune
+	code := p[0][0]
+
+	// Unless the kitty protocol is enabled, 'u' sequences come from
+	// xterm's formatOtherKeys, which uses the same encoding of the
+	// modifiers and key code as CSI 27 ~ does.
+	xterm := t.kittykeys == 0 && final == 'u'
+	if final == '~' && code == 27 {
+		final, code, xterm = 'u', p[2][0], true
+	}
+
+	switch final {
+	case 'u':
+		if k, ok := kittyKeys[code]; ok {
+			key = k
+		} else if code >= 57376 && code <= 57398 {
+			key = KeyF13 + Key(code-57376)
+		} else if code >= 57399 && code < 57399+len(kittyKeypad) {
+			ch = rune(kittyKeypad[code-57399])
+		} else if code >= 57344 && code <= 63743 {
+			// Other functional keys (modifiers, media keys,
+			// and so forth) are not reported.
+			return nil
+		} else {
+			ch = rune(code)
+		}
+	case '~':
+		if key = kittyTildeKeys[code]; key == 0 {
+			return nil
+		}
+	default:
+		key = kittyLetterKeys[final]
+	}
+
+	mod := ModNone
+	if xterm {
+		mod = xtermModifiers(p[1][0])
+	} else if m := p[1][0] - 1; m > 0 {
+		if m&1 != 0 {
+			mod |= ModShift
+		}
+		if m&2 != 0 {
+			mod |= ModAlt
+		}
+		if m&4 != 0 {
+			mod |= ModCtrl
+		}
+		if m&8 != 0 {
+			mod |= ModSuper
+		}
+		if m&16 != 0 {
+			mod |= ModHyper
+		}
+		if m&32 != 0 {
+			mod |= ModMeta
+		}
+	}
+
+	if key == KeyRune {
+		// Control keys are reported the same way as the legacy
+		// encoding does, but with the modifiers we know about.
+		if mod&ModCtrl != 0 && (ch == ' ' || (ch >= '@' && ch <= '_') ||
+			(ch >= 'a' && ch <= 'z')) {
+			key = Key(ch & 0x1f)
+			ch = rune(key)
+		} else if mod&ModShift != 0 {
+			// Shifted text is reported as itself, without
+			// ModShift, just like the legacy encoding.  Xterm
+			// has already applied the shift to the code.
+			if xterm {
+				mod &^= ModShift
+			} else if p[0][1] != 0 {
+				ch = rune(p[0][1])
+				mod &^= ModShift
+			} else if u := unicode.ToUpper(ch); u != ch {
+				ch = u
+				mod &^= ModShift
+			}
+		}
+	} else if key < ' ' || key == KeyDEL {
+		if key == KeyTab && mod&ModShift != 0 {
+			key = KeyBacktab
+			mod &^= ModShift
+		} else {
+			ch = rune(key)
+		}
+	}
+
+	ev := NewEventKey(key, ch, mod, t.escbuf.String())
+	switch p[1][1] {
+	case 2:
+		ev.etype = KeyEventRepeat
+	case 3:
+		ev.etype = KeyEventRelease
+	}
+	if key == KeyRune || key < ' ' {
+		ev.shifted = rune(p[0][1])
+		ev.base = rune(p[0][2])
+	}
+	return ev
+}
+
 func (t *tScreen) parseFunctionKey(buf *bytes.Buffer, evs *[]Event) (bool, bool) {
 	b := buf.Bytes()
 	partial := false
@@ -1447,15 +2671,21 @@ func (t *tScreen) parseBracketedPaste(buf *bytes.Buffer, evs *[]Event) (bool, bo
 	return false, false
 }
 
+// scanInput parses the input, and posts the events found.  Events that
+// do not fit in the queue (other than mouse events, which are dropped)
+// are kept until the main loop can deliver them, rather than waiting for
+// room, so that the main loop can go on reading input.  Otherwise replies
+// to queries made by the goroutine that reads events would not be read
+// while it waits for them.
 func (t *tScreen) scanInput(buf *bytes.Buffer, expire bool) {
 	evs := t.collectEventsFromInput(buf, expire)
 
 	for _, ev := range evs {
-		switch ev.(type) {
-		case *EventMouse:
-			t.PostEvent(ev)
-		default:
-			t.PostEventWait(ev)
+		if len(t.evpend) == 0 && t.PostEvent(ev) == nil {
+			continue
+		}
+		if _, ok := ev.(*EventMouse); !ok {
+			t.evpend = append(t.evpend, ev)
 		}
 	}
 }
@@ -1494,12 +2724,48 @@ func (t *tScreen) collectEventsFromInput(buf *bytes.Buffer, expire bool) []Event
 			partials++
 		}
 
+		if part, comp := t.parseDeviceReport(buf); comp {
+			continue
+		} else if part {
+			partials++
+		}
+
+		if part, comp := t.parseVersion(buf); comp {
+			continue
+		} else if part {
+			partials++
+		}
+
+		if part, comp := t.parseColorReport(buf); comp {
+			continue
+		} else if part {
+			partials++
+		}
+
+		if part, comp := t.parseCellSize(buf); comp {
+			continue
+		} else if part {
+			partials++
+		}
+
+		if part, comp := t.parseFocus(buf, &res); comp {
+			continue
+		} else if part {
+			partials++
+		}
+
 		if part, comp := t.parseRune(buf, &res); comp {
 			continue
 		} else if part {
 			partials++
 		}
 
+		if part, comp := t.parseExtendedKey(buf, &res); comp {
+			continue
+		} else if part {
+			partials++
+		}
+
 		if part, comp := t.parseFunctionKey(buf, &res); comp {
 			continue
 		} else if part {
@@ -1572,23 +2838,45 @@ func (t *tScreen) collectEventsFromInput(buf *bytes.Buffer, expire bool) []Event
 	return res
 }
 
+// maxPendingEvents is the number of events that may wait for room in the
+// event queue before we stop reading input.
+const maxPendingEvents = 1024
+
 func (t *tScreen) mainLoop() {
 	buf := &bytes.Buffer{}
 	t.escbuf = &bytes.Buffer{}
 	for {
+		// Deliver the events kept by scanInput as there is room, and
+		// stop reading input if too many are waiting.
+		var evq chan Event
+		var next Event
+		keychan := t.keychan
+		if len(t.evpend) > 0 {
+			evq = t.evch
+			next = t.evpend[0]
+		}
+		if len(t.evpend) >= maxPendingEvents {
+			keychan = nil
+		}
+
 		select {
 		case <-t.quit:
 			close(t.indoneq)
 			return
-		case <-t.sigwinch:
+		case evq <- next:
+			t.evpend[0] = nil
+			t.evpend = t.evpend[1:]
+			continue
+		case <-t.resizeq:
 			t.Lock()
-			t.cx = -1
-			t.cy = -1
-			t.resize()
-			t.cells.Invalidate()
-			t.draw()
+			if !t.frame.deferDraw(frameRedraw) {
+				t.redraw()
+			}
 			t.Unlock()
 			continue
+		case <-t.drawtimer.C:
+			t.drawScheduled()
+			continue
 		case <-t.keytimer.C:
 			// If the timer fired, and the current time
 			// is after the expiration of the escape sequence,
@@ -1609,7 +2897,7 @@ func (t *tScreen) mainLoop() {
 				}
 				t.keytimer.Reset(time.Millisecond * 50)
 			}
-		case chunk := <-t.keychan:
+		case chunk := <-keychan:
 			buf.Write(chunk)
 			t.keyexpire = time.Now().Add(time.Millisecond * 50)
 			t.scanInput(buf, false)
@@ -1626,10 +2914,29 @@ func (t *tScreen) mainLoop() {
 	}
 }
 
-func (t *tScreen) inputLoop() {
+// inputLoop reads from the terminal, and passes the data read to
+// the main loop for parsing.  It runs until the stop channel is closed
+// (and the pending read is interrupted), at which point it closes the
+// done channel.
+func (t *tScreen) inputLoop(stopq, done chan struct{}, held [][]byte) {
+	defer close(done)
+	for i, chunk := range held {
+		if !t.sendInput(stopq, chunk) {
+			t.holdInput(held[i+1:]...)
+			return
+		}
+	}
 	for {
 		chunk := make([]byte, 4096)
-		n, e := t.in.Read(chunk)
+		n, e := t.tty.Read(chunk)
+		select {
+		case <-stopq:
+			// Anything we did read is kept, so that it can
+			// be processed normally after resuming.
+			t.holdInput(chunk[:n])
+			return
+		default:
+		}
 		switch e {
 		case io.EOF:
 		case nil:
@@ -1637,20 +2944,74 @@ func (t *tScreen) inputLoop() {
 			t.PostEvent(NewEventError(e))
 			return
 		}
-		t.keychan <- chunk[:n]
+		if !t.sendInput(stopq, chunk[:n]) {
+			return
+		}
+	}
+}
+
+// sendInput passes input to the main loop.  The main loop may not be
+// taking input (if too many events are waiting), so if the input loop is
+// stopped meanwhile, the input is kept instead, and false is returned.
+func (t *tScreen) sendInput(stopq chan struct{}, chunk []byte) bool {
+	select {
+	case t.keychan <- chunk:
+		return true
+	case <-stopq:
+		t.holdInput(chunk)
+		return false
+	case <-t.quit:
+		return false
+	}
+}
+
+// holdInput keeps input read by a stopped input loop, for the next.
+func (t *tScreen) holdInput(chunks ...[]byte) {
+	t.Lock()
+	for _, chunk := range chunks {
+		if len(chunk) > 0 {
+			t.held = append(t.held, chunk)
+		}
 	}
+	t.Unlock()
 }
 
 func (t *tScreen) Sync() {
 	t.Lock()
+	if !t.frame.deferDraw(frameSync) {
+		t.sync()
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) sync() {
 	t.cx = -1
 	t.cy = -1
-	if !t.fini {
+	if !t.fini && !t.suspended {
 		t.resize()
+		t.clearImages()
 		t.clear = true
 		t.cells.Invalidate()
 		t.draw()
 	}
+}
+
+func (t *tScreen) BeginFrame() {
+	t.Lock()
+	t.frame.begin()
+	t.Unlock()
+}
+
+func (t *tScreen) EndFrame() {
+	t.Lock()
+	switch t.frame.end() {
+	case frameShow:
+		t.scheduleShow()
+	case frameRedraw:
+		t.redraw()
+	case frameSync:
+		t.sync()
+	}
 	t.Unlock()
 }
 
@@ -1710,6 +3071,73 @@ func (t *tScreen) HasKey(k Key) bool {
 
 func (t *tScreen) Resize(int, int, int, int) {}
 
+func (t *tScreen) Beep() error {
+	t.writeString(string(byte(7)))
+	return nil
+}
+
+func (t *tScreen) SetTitle(title string) {
+	t.Lock()
+	t.title = stripControls(title)
+	t.hastitle = true
+	if !t.fini && !t.suspended {
+		t.sendTitle()
+	}
+	t.Unlock()
+}
+
+func (t *tScreen) SetIconName(name string) {
+	t.Lock()
+	t.icon = stripControls(name)
+	t.hasicon = true
+	if !t.fini && !t.suspended {
+		t.sendTitle()
+	}
+	t.Unlock()
+}
+
+// sendTitle emits the window title and icon name, if they have been
+// set.  The first time, the terminal's own values are saved on its
+// title stack, so that disengage can restore them.
+func (t *tScreen) sendTitle() {
+	ti := t.ti
+	if !t.hastitle && !t.hasicon {
+		return
+	}
+	if !t.titlesent && t.isAnsi() {
+		t.TPuts(titlePush)
+		t.titlesent = true
+	}
+	if t.hastitle {
+		if ti.EnterTitle != "" && ti.ExitTitle != "" {
+			t.TPuts(ti.TParm(ti.EnterTitle, 0))
+			t.writeString(t.title)
+			t.TPuts(ti.ExitTitle)
+		} else if t.isAnsi() {
+			t.writeString(fmt.Sprintf(titleSet, t.title))
+		}
+	}
+	if t.hasicon && t.isAnsi() {
+		t.writeString(fmt.Sprintf(iconSet, t.icon))
+	}
+}
+
+// stripControls removes control characters, which could otherwise be
+// used to terminate an escape sequence early.
+func stripControls(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isControl returns true for the C0 and C1 control characters, and DEL.
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f || (r >= 0x80 && r < 0xa0)
+}
+
 func (t *tScreen) GetClipboard(register string) error {
 	if len(register) <= 0 {
 		return errors.New("No register provided")
@@ -18,19 +18,8 @@ package tcell
 
 // This stub file is for systems that have no termios.
 
-type termiosPrivate struct{}
-
-func (t *tScreen) termioInit() error {
-	return ErrNoScreen
-}
-
-func (t *tScreen) termioFini() {
-}
-
-func (t *tScreen) getWinSize() (int, int, error) {
-	return 0, 0, ErrNoScreen
-}
-
-func (t *tScreen) Beep() error {
-	return ErrNoScreen
+// NewDevTty is not supported on this platform, and always returns
+// ErrNoScreen.
+func NewDevTty() (Tty, error) {
+	return nil, ErrNoScreen
 }
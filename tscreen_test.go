@@ -0,0 +1,1560 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zyedidia/tcell/v2/terminfo"
+)
+
+// testTty is a Tty that records output, and lets the test supply input.
+type testTty struct {
+	w       int
+	h       int
+	out     bytes.Buffer
+	inq     chan []byte
+	stopq   chan struct{}
+	started bool
+	closed  bool
+	resize  func()
+	replies [][2]string // queries the terminal answers, and the replies
+
+	sync.Mutex
+}
+
+var errTtyStopped = errors.New("tty stopped")
+
+func newTestTty(w, h int) *testTty {
+	return &testTty{w: w, h: h, inq: make(chan []byte, 10),
+		replies: [][2]string{{"\x1b[c", "\x1b[?1;2c"}}}
+}
+
+func (tt *testTty) Start() error {
+	tt.Lock()
+	tt.started = true
+	tt.stopq = make(chan struct{})
+	tt.Unlock()
+	return nil
+}
+
+func (tt *testTty) Stop() error {
+	tt.Lock()
+	if tt.started {
+		tt.started = false
+		close(tt.stopq)
+	}
+	tt.Unlock()
+	return nil
+}
+
+func (tt *testTty) WindowSize() (int, int, error) {
+	tt.Lock()
+	defer tt.Unlock()
+	return tt.w, tt.h, nil
+}
+
+func (tt *testTty) NotifyResize(cb func()) {
+	tt.Lock()
+	tt.resize = cb
+	tt.Unlock()
+}
+
+func (tt *testTty) Read(b []byte) (int, error) {
+	tt.Lock()
+	stopq := tt.stopq
+	tt.Unlock()
+	select {
+	case in := <-tt.inq:
+		return copy(b, in), nil
+	case <-stopq:
+		return 0, errTtyStopped
+	}
+}
+
+func (tt *testTty) Write(b []byte) (int, error) {
+	tt.Lock()
+	defer tt.Unlock()
+	for _, r := range tt.replies {
+		if bytes.Contains(b, []byte(r[0])) {
+			tt.inq <- []byte(r[1])
+		}
+	}
+	return tt.out.Write(b)
+}
+
+func (tt *testTty) Close() error {
+	tt.Lock()
+	tt.closed = true
+	tt.Unlock()
+	return nil
+}
+
+// Output returns (and discards) everything written so far.
+func (tt *testTty) Output() string {
+	tt.Lock()
+	defer tt.Unlock()
+	s := tt.out.String()
+	tt.out.Reset()
+	return s
+}
+
+// Discard discards everything written so far, returning its length.
+func (tt *testTty) Discard() int {
+	tt.Lock()
+	defer tt.Unlock()
+	n := tt.out.Len()
+	tt.out.Reset()
+	return n
+}
+
+func (tt *testTty) SetSize(w, h int) {
+	tt.Lock()
+	tt.w, tt.h = w, h
+	cb := tt.resize
+	tt.Unlock()
+	if cb != nil {
+		cb()
+	}
+}
+
+func mkTestTtyScreen(t testing.TB, term string) (Screen, *testTty) {
+	return mkTestTtyScreenSize(t, term, 80, 24)
+}
+
+func mkTestTtyScreenSize(t testing.TB, term string, w, h int) (Screen, *testTty) {
+	os.Setenv("LANG", "en_US.UTF-8")
+	tty := newTestTty(w, h)
+	s, e := NewTerminfoScreenFromTty(tty, term)
+	if e != nil {
+		t.Fatalf("Failed to get terminfo screen: %v", e)
+	}
+	if e = s.Init(); e != nil {
+		t.Fatalf("Failed to initialize screen: %v", e)
+	}
+	return s, tty
+}
+
+func waitEvent(t *testing.T, s Screen) Event {
+	evch := make(chan Event, 1)
+	go func() {
+		evch <- s.PollEvent()
+	}()
+	select {
+	case ev := <-evch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for event")
+	}
+	return nil
+}
+
+func TestTtyScreen(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+
+	if !tty.started {
+		t.Fatalf("Tty not started")
+	}
+	if w, h := s.Size(); w != 80 || h != 24 {
+		t.Errorf("Size should be 80, 24, was %v, %v", w, h)
+	}
+	if _, ok := waitEvent(t, s).(*EventResize); !ok {
+		t.Errorf("Expected initial resize event")
+	}
+	s.Show()
+	tty.Output()
+
+	s.SetContent(3, 2, '@', nil, StyleDefault)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[3;4H@") {
+		t.Errorf("Content not drawn: %q", out)
+	}
+
+	tty.inq <- []byte("x")
+	if ev, ok := waitEvent(t, s).(*EventKey); !ok || ev.Rune() != 'x' {
+		t.Errorf("Expected key event for x, got %v", ev)
+	}
+
+	tty.SetSize(40, 10)
+	if ev, ok := waitEvent(t, s).(*EventResize); !ok {
+		t.Errorf("Expected resize event")
+	} else if w, h := ev.Size(); w != 40 || h != 10 {
+		t.Errorf("Resize should be 40, 10, was %v, %v", w, h)
+	}
+
+	s.Fini()
+	if tty.started || !tty.closed {
+		t.Errorf("Tty not stopped and closed")
+	}
+}
+
+func TestTtyScreenSuspend(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	if e := s.Suspend(); e != nil {
+		t.Fatalf("Failed to suspend: %v", e)
+	}
+	if tty.started {
+		t.Errorf("Tty not stopped on suspend")
+	}
+	if out := tty.Output(); !strings.Contains(out, "\x1b[?1049l") {
+		t.Errorf("Alternate screen not exited: %q", out)
+	}
+
+	// Input arriving while suspended is delivered after resuming.
+	tty.inq <- []byte("z")
+
+	if e := s.Resume(); e != nil {
+		t.Fatalf("Failed to resume: %v", e)
+	}
+	if !tty.started {
+		t.Errorf("Tty not restarted on resume")
+	}
+	if out := tty.Output(); !strings.Contains(out, "\x1b[?1049h") {
+		t.Errorf("Alternate screen not entered: %q", out)
+	}
+	for {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); ok {
+			continue
+		}
+		if ev, ok := ev.(*EventKey); !ok || ev.Rune() != 'z' {
+			t.Errorf("Expected key event for z, got %v", ev)
+		}
+		break
+	}
+}
+
+func TestTtyScreenSuspendQueueFull(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	// Enough keys that the main loop stops taking input, and then more,
+	// which the input loop cannot pass on.
+	keys := strings.Repeat("a", maxPendingEvents+100)
+	tty.inq <- []byte(keys)
+	go func() {
+		for r := 'b'; r <= 'z'; r++ {
+			tty.inq <- []byte{byte(r)}
+		}
+	}()
+	keys += "bcdefghijklmnopqrstuvwxyz"
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		done <- s.Suspend()
+	}()
+	select {
+	case e := <-done:
+		if e != nil {
+			t.Errorf("Failed to suspend: %v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Suspend did not return")
+	}
+	if e := s.Resume(); e != nil {
+		t.Fatalf("Failed to resume: %v", e)
+	}
+
+	// No input is lost.
+	got := make([]byte, 0, len(keys))
+	for len(got) < len(keys) {
+		if ek, ok := waitEvent(t, s).(*EventKey); ok {
+			got = append(got, byte(ek.Rune()))
+		}
+	}
+	if string(got) != keys {
+		t.Errorf("Input lost: got %d keys, ending %q", len(got), got[len(got)-30:])
+	}
+}
+
+func TestTtyScreenMouseSuspended(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	s.EnableMouse()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[?1000h") {
+		t.Errorf("Mouse not enabled: %q", out)
+	}
+	s.Suspend()
+	tty.Output()
+
+	// Nothing is sent while suspended, but the mode is restored later.
+	s.DisableMouse()
+	s.EnableMouse()
+	if out := tty.Output(); out != "" {
+		t.Errorf("Mouse mode sent while suspended: %q", out)
+	}
+	s.Resume()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[?1000h") {
+		t.Errorf("Mouse not enabled on resume: %q", out)
+	}
+}
+
+func TestInlineScreen(t *testing.T) {
+	os.Setenv("LANG", "en_US.UTF-8")
+	tty := newTestTty(80, 24)
+	s, e := NewInlineTerminfoScreen(tty, "xterm", 3)
+	if e != nil {
+		t.Fatalf("Failed to get inline screen: %v", e)
+	}
+	if e = s.Init(); e != nil {
+		t.Fatalf("Failed to initialize screen: %v", e)
+	}
+	if w, h := s.Size(); w != 80 || h != 3 {
+		t.Errorf("Size should be 80, 3, was %v, %v", w, h)
+	}
+	out := tty.Output()
+	if strings.Contains(out, "\x1b[?1049h") {
+		t.Errorf("Alternate screen used: %q", out)
+	}
+	if !strings.Contains(out, "\r\n\n\x1b[2A\x1b[J") {
+		t.Errorf("Lines not reserved: %q", out)
+	}
+
+	s.Show()
+	tty.Output()
+	s.SetContent(4, 0, '@', nil, StyleDefault)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[2A\r\x1b[4C@") {
+		t.Errorf("Content not drawn relatively: %q", out)
+	}
+
+	s.Println("done")
+	out = tty.Output()
+	if !strings.Contains(out, "\r\x1b(B\x1b[m\x1b[Jdone\r\n\x1b(B\x1b[m\r\n\n\x1b[2A\x1b[J") {
+		t.Errorf("Line not printed above region: %q", out)
+	}
+	if !strings.Contains(out, "@") {
+		t.Errorf("Region not redrawn: %q", out)
+	}
+
+	s.Println("a\tb\x1b[1m\tc\n\u4e16\td\u009b2J\x7f")
+	out = tty.Output()
+	if !strings.Contains(out, "a       b[1m    c\r\n\u4e16      d2J\r\n") {
+		t.Errorf("Tabs not expanded: %q", out)
+	}
+
+	s.Fini()
+	out = tty.Output()
+	if strings.Contains(out, "\x1b[?1049l") || strings.Contains(out, "\x1b[H\x1b[2J") {
+		t.Errorf("Screen cleared on exit: %q", out)
+	}
+	if !strings.HasSuffix(out, "\r\n\x1b[?1l\x1b>\x1b[?1000l\x1b[?1002l\x1b[?1006l\x1b[?2004l") {
+		t.Errorf("Cursor not moved below region: %q", out)
+	}
+}
+
+func TestTtyScreenTitle(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	tty.Output()
+
+	s.SetTitle("hello\x1b.go")
+	if out := tty.Output(); out != "\x1b[22;0t\x1b]2;hello.go\a" {
+		t.Errorf("Title not set: %q", out)
+	}
+	s.SetIconName("hello")
+	if out := tty.Output(); out != "\x1b]2;hello.go\a\x1b]1;hello\a" {
+		t.Errorf("Icon name not set: %q", out)
+	}
+
+	s.Fini()
+	out := tty.Output()
+	if i, j := strings.Index(out, "\x1b[23;0t"), strings.Index(out, "\x1b[?1049l"); i < 0 || i > j {
+		t.Errorf("Title not restored before leaving alternate screen: %q", out)
+	}
+}
+
+func TestTtyScreenCursorStyle(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	tty.Output()
+
+	s.SetCursorStyle(CursorStyleBlinkingBar)
+	if out := tty.Output(); out != "\x1b[5 q" {
+		t.Errorf("Cursor style not set: %q", out)
+	}
+	s.SetCursorColor(NewRGBColor(0x12, 0x34, 0x56))
+	if out := tty.Output(); out != "\x1b]12;#123456\a" {
+		t.Errorf("Cursor color not set: %q", out)
+	}
+
+	s.Fini()
+	out := tty.Output()
+	if !strings.Contains(out, "\x1b[0 q") {
+		t.Errorf("Cursor style not restored: %q", out)
+	}
+	if !strings.Contains(out, "\x1b]112\a") {
+		t.Errorf("Cursor color not restored: %q", out)
+	}
+}
+
+func TestTtyScreenFocus(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	tty.Output()
+
+	s.EnableFocus()
+	if out := tty.Output(); out != "\x1b[?1004h" {
+		t.Errorf("Focus not enabled: %q", out)
+	}
+
+	tty.inq <- []byte("\x1b[O\x1b[Ia")
+	var evs []Event
+	for len(evs) < 3 {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); !ok {
+			evs = append(evs, ev)
+		}
+	}
+	if ev, ok := evs[0].(*EventFocus); !ok || ev.Focused() {
+		t.Errorf("Expected focus out event, got %v", evs[0])
+	}
+	if ev, ok := evs[1].(*EventFocus); !ok || !ev.Focused() {
+		t.Errorf("Expected focus in event, got %v", evs[1])
+	} else if ev.EscSeq() != "\x1b[I" {
+		t.Errorf("Wrong escape sequence %q", ev.EscSeq())
+	}
+	if ev, ok := evs[2].(*EventKey); !ok || ev.Rune() != 'a' {
+		t.Errorf("Expected key event for a, got %v", evs[2])
+	}
+
+	s.Fini()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[?1004l") {
+		t.Errorf("Focus not disabled: %q", out)
+	}
+}
+
+func TestTtyScreenSyncOutput(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	// The terminal is not asked unless it is probed.
+	if out := tty.Output(); strings.Contains(out, "\x1b[?2026") {
+		t.Errorf("Synchronized output queried without probing: %q", out)
+	}
+	s.SetContent(0, 0, 'a', nil, StyleDefault)
+	s.Show()
+	if out := tty.Output(); strings.Contains(out, "\x1b[?2026") {
+		t.Errorf("Synchronized output used without support: %q", out)
+	}
+
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b[?2026$p", "\x1b[?2026;2$y"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+	if e := s.Probe(); e != nil {
+		t.Fatalf("Probe failed: %v", e)
+	}
+	tty.Output()
+
+	s.SetContent(0, 0, 'c', nil, StyleDefault)
+	s.Show()
+	out := tty.Output()
+	if !strings.HasPrefix(out, "\x1b[?2026h") || !strings.HasSuffix(out, "\x1b[?2026l") {
+		t.Errorf("Frame not synchronized: %q", out)
+	}
+}
+
+func TestTtyScreenSyncOutputDisabled(t *testing.T) {
+	os.Setenv("TCELL_SYNCOUTPUT", "disable")
+	defer os.Unsetenv("TCELL_SYNCOUTPUT")
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b[?2026$p", "\x1b[?2026;2$y"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+	if e := s.Probe(); e != nil {
+		t.Fatalf("Probe failed: %v", e)
+	}
+	if out := tty.Output(); strings.Contains(out, "\x1b[?2026") {
+		t.Errorf("Synchronized output queried: %q", out)
+	}
+	tty.inq <- []byte("\x1b[?2026;2$y")
+	s.SetContent(0, 0, 'c', nil, StyleDefault)
+	time.Sleep(10 * time.Millisecond)
+	s.Show()
+	if out := tty.Output(); strings.Contains(out, "\x1b[?2026") {
+		t.Errorf("Synchronized output used: %q", out)
+	}
+}
+
+func TestTtyScreenUnderline(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm-truecolor")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	// Without terminfo support, a plain underline is used.
+	st := StyleDefault.UnderlineStyle(UnderlineStyleCurly).UnderlineColor(ColorRed)
+	s.SetContent(0, 0, 'a', nil, st)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[4m") ||
+		strings.Contains(out, "\x1b[4:") || strings.Contains(out, "\x1b[58") {
+		t.Errorf("Plain underline not used: %q", out)
+	}
+}
+
+func TestTtyScreenUnderlineStyles(t *testing.T) {
+	os.Setenv("TCELL_UNDERLINE", "enable")
+	defer os.Unsetenv("TCELL_UNDERLINE")
+	s, tty := mkTestTtyScreen(t, "xterm-truecolor")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	st := StyleDefault.UnderlineStyle(UnderlineStyleCurly).UnderlineColor(ColorRed)
+	s.SetContent(0, 0, 'a', nil, st)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[4:3m\x1b[58:5:9m") {
+		t.Errorf("Curly red underline not used: %q", out)
+	}
+
+	st = StyleDefault.UnderlineStyle(UnderlineStyleDouble).UnderlineColor(NewHexColor(0x123456))
+	s.SetContent(0, 0, 'a', nil, st)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[4:2m\x1b[58:2::18:52:86m") {
+		t.Errorf("Double RGB underline not used: %q", out)
+	}
+
+	s.SetContent(0, 0, 'a', nil, StyleDefault.Underline(true))
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[4m") || strings.Contains(out, "\x1b[58") {
+		t.Errorf("Plain underline not used: %q", out)
+	}
+}
+
+func TestTtyScreenHyperlink(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	link := StyleDefault.Url("https://example.com/a;b")
+	s.SetContent(0, 0, 'a', nil, link)
+	s.SetContent(1, 0, 'b', nil, link)
+	s.SetContent(2, 0, 'c', nil, StyleDefault)
+	s.SetContent(3, 0, 'd', nil, link.UrlId("x:1"))
+	s.Show()
+	out := tty.Output()
+	// The link is closed when the style changes, and at the end.
+	for _, expect := range []string{
+		"\x1b]8;;https://example.com/a;b\x1b\\ab",
+		"\x1b]8;;\x1b\\c",
+		"\x1b]8;id=x1;https://example.com/a;b\x1b\\d\x1b]8;;\x1b\\",
+	} {
+		i := strings.Index(out, expect)
+		if i < 0 {
+			t.Fatalf("Hyperlinks not drawn properly: %q", out)
+		}
+		out = out[i+len(expect):]
+	}
+}
+
+func mkTestImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+		}
+	}
+	return img
+}
+
+func TestTtyScreenImageUnsupported(t *testing.T) {
+	s, _ := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	if e := s.ShowImage(0, 0, 2, 2, mkTestImage()); e != ErrNotSupported {
+		t.Errorf("Expected ErrNotSupported, got %v", e)
+	}
+}
+
+func TestTtyScreenKittyImage(t *testing.T) {
+	os.Setenv("TCELL_IMAGES", "kitty")
+	defer os.Unsetenv("TCELL_IMAGES")
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	s.SetContent(1, 1, 'X', nil, StyleDefault)
+	s.SetContent(3, 1, 'Y', nil, StyleDefault)
+	if e := s.ShowImage(1, 1, 2, 2, mkTestImage()); e != nil {
+		t.Fatalf("Failed to show image: %v", e)
+	}
+	s.Show()
+	out := tty.Output()
+	// The cursor moves back from after the Y, to the image.
+	if !strings.Contains(out, "Y\b\b\b\x1b_Ga=t,f=100,i=1,q=2,m=0;") ||
+		!strings.Contains(out, "\x1b\\\x1b_Ga=p,i=1,p=1,c=2,r=2,C=1,q=2\x1b\\") {
+		t.Errorf("Image not shown: %q", out)
+	}
+	if strings.Contains(out, "X") || !strings.Contains(out, "Y") {
+		t.Errorf("Cells beneath image drawn: %q", out)
+	}
+
+	// Once shown, the image is not sent again.
+	s.SetContent(3, 1, 'Z', nil, StyleDefault)
+	s.Show()
+	if out := tty.Output(); strings.Contains(out, "\x1b_G") {
+		t.Errorf("Image sent again: %q", out)
+	}
+
+	s.ClearImages()
+	s.Show()
+	out = tty.Output()
+	if !strings.Contains(out, "\x1b_Ga=d,d=I,i=1,q=2\x1b\\") || !strings.Contains(out, "X") {
+		t.Errorf("Image not cleared: %q", out)
+	}
+
+	// Images off the top or left of the screen are refused.
+	if e := s.ShowImage(-1, 0, 2, 2, mkTestImage()); e != ErrOffScreen {
+		t.Errorf("Expected ErrOffScreen, got %v", e)
+	}
+	s.Show()
+	if out := tty.Output(); strings.Contains(out, "\x1b_G") {
+		t.Errorf("Image off the screen sent: %q", out)
+	}
+}
+
+func TestTtyScreenSixelImage(t *testing.T) {
+	os.Setenv("TCELL_IMAGES", "sixel")
+	defer os.Unsetenv("TCELL_IMAGES")
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[16t") {
+		t.Errorf("Cell size not queried: %q", out)
+	}
+
+	tty.inq <- []byte("\x1b[6;3;2t")
+	time.Sleep(10 * time.Millisecond)
+	if e := s.ShowImage(1, 1, 2, 2, mkTestImage()); e != nil {
+		t.Fatalf("Failed to show image: %v", e)
+	}
+	s.Show()
+	// The image is 4 by 6 pixels: red, in a single band of sixels.
+	out := tty.Output()
+	if !strings.Contains(out, "\x1b[2;2H\x1bP0;1;0q\"1;1;4;6#") ||
+		!strings.Contains(out, ";2;100;0;0#") ||
+		!strings.Contains(out, "!4~-\x1b\\") {
+		t.Errorf("Sixel image not shown: %q", out)
+	}
+
+	// A sixel image on the last line would scroll the screen.
+	s.ClearImages()
+	s.ShowImage(0, 22, 2, 2, mkTestImage())
+	s.Show()
+	if out := tty.Output(); strings.Contains(out, "\x1bP") {
+		t.Errorf("Sixel image shown on last line: %q", out)
+	}
+}
+
+func TestTtyScreenProbe(t *testing.T) {
+	os.Setenv("LANG", "en_US.UTF-8")
+	tty := newTestTty(80, 24)
+	tty.replies = [][2]string{
+		{"\x1b[>0q", "\x1bP>|kitty(0.31.0)\x1b\\"},
+		{"\x1b[>c", "\x1b[>1;4000;29c"},
+		{"\x1b[?1004$p", "\x1b[?1004;0$y"},
+		{"\x1b[?2004$p", "\x1b[?2004;2$y"},
+		{"\x1b[?2026$p", "\x1b[?2026;2$y"},
+		{"\x1b[?u", "\x1b[?0u"},
+		{"\x1b[c", "\x1b[?62;4;22c"},
+	}
+	s, e := NewTerminfoScreenFromTty(tty, "xterm")
+	if e != nil {
+		t.Fatalf("Failed to get terminfo screen: %v", e)
+	}
+	if e = s.Init(); e != nil {
+		t.Fatalf("Failed to initialize screen: %v", e)
+	}
+	defer s.Fini()
+	if caps := s.Capabilities(); caps.Probed {
+		t.Errorf("Probed without being asked: %+v", caps)
+	}
+	if e = s.Probe(); e != nil {
+		t.Errorf("Probe failed: %v", e)
+	}
+
+	caps := s.Capabilities()
+	if !caps.Probed || caps.Class != 62 || len(caps.Attributes) != 2 ||
+		caps.Attributes[0] != 4 || caps.Attributes[1] != 22 {
+		t.Errorf("Bad primary attributes: %+v", caps)
+	}
+	if caps.TerminalID != 1 || caps.Firmware != 4000 {
+		t.Errorf("Bad secondary attributes: %+v", caps)
+	}
+	if caps.Version != "kitty(0.31.0)" {
+		t.Errorf("Bad version: %q", caps.Version)
+	}
+	if !caps.Sixel || !caps.SyncOutput || !caps.BracketedPaste ||
+		caps.FocusEvents || !caps.KittyKeyboard {
+		t.Errorf("Bad features: %+v", caps)
+	}
+	if e := s.ShowImage(0, 0, 1, 1, mkTestImage()); e != nil {
+		t.Errorf("Images not supported: %v", e)
+	}
+
+	// None of the replies are delivered as events.
+	tty.inq <- []byte("a")
+	for {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); ok {
+			continue
+		}
+		if ek, ok := ev.(*EventKey); !ok || ek.Rune() != 'a' {
+			t.Errorf("Expected key a, got %#v", ev)
+		}
+		break
+	}
+}
+
+func TestTtyScreenProbeLongReply(t *testing.T) {
+	os.Setenv("LANG", "en_US.UTF-8")
+	os.Setenv("TCELL_PROBE", "enable")
+	defer os.Unsetenv("TCELL_PROBE")
+	tty := newTestTty(80, 24)
+	tty.replies = [][2]string{{"\x1b[c",
+		"\x1b[?65;1;2;3;4;5;6;7;8;9;10;11;12;13;14;15;16;17;18c"}}
+	s, e := NewTerminfoScreenFromTty(tty, "xterm")
+	if e != nil {
+		t.Fatalf("Failed to get terminfo screen: %v", e)
+	}
+	if e = s.Init(); e != nil {
+		t.Fatalf("Failed to initialize screen: %v", e)
+	}
+	defer s.Fini()
+
+	caps := s.Capabilities()
+	if !caps.Probed || caps.Class != 65 || len(caps.Attributes) != 15 ||
+		caps.Attributes[14] != 15 || !caps.Sixel {
+		t.Errorf("Bad primary attributes: %+v", caps)
+	}
+	tty.inq <- []byte("a")
+	for {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); ok {
+			continue
+		}
+		if ek, ok := ev.(*EventKey); !ok || ek.Rune() != 'a' {
+			t.Errorf("Expected key a, got %#v", ev)
+		}
+		break
+	}
+}
+
+func TestTtyScreenProbeTimeout(t *testing.T) {
+	os.Setenv("LANG", "en_US.UTF-8")
+	tty := newTestTty(80, 24)
+	tty.replies = nil
+	s, e := NewTerminfoScreenFromTty(tty, "xterm")
+	if e != nil {
+		t.Fatalf("Failed to get terminfo screen: %v", e)
+	}
+	if e = s.Init(); e != nil {
+		t.Fatalf("Failed to initialize screen: %v", e)
+	}
+	defer s.Fini()
+	if out := tty.Output(); strings.Contains(out, "\x1b[c") {
+		t.Errorf("Terminal probed during Init: %q", out)
+	}
+	start := time.Now()
+	if e = s.Probe(); e != ErrNotSupported {
+		t.Errorf("Expected ErrNotSupported, got %v", e)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Probe did not time out")
+	}
+	if out := tty.Output(); !strings.Contains(out, "\x1b[c") {
+		t.Errorf("Terminal not probed: %q", out)
+	}
+	if caps := s.Capabilities(); caps.Probed {
+		t.Errorf("Capabilities should not be probed: %+v", caps)
+	}
+}
+
+func TestTtyScreenDefaultColors(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b]10;?", "\x1b]10;rgb:ffff/8080/0000\x1b\\"},
+		{"\x1b]11;?", "\x1b]11;rgb:1/22/333\a"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+	fg, bg, e := s.QueryDefaultColors()
+	if e != nil {
+		t.Fatalf("Failed to query colors: %v", e)
+	}
+	if fg != NewHexColor(0xff8000) || bg != NewHexColor(0x112233) {
+		t.Errorf("Bad colors: %x %x", fg.Hex(), bg.Hex())
+	}
+
+	// A terminal that does not know the query just answers DA1.
+	tty.Lock()
+	tty.replies = [][2]string{{"\x1b[c", "\x1b[?1;2c"}}
+	tty.Unlock()
+	if _, _, e := s.QueryDefaultColors(); e != ErrNotSupported {
+		t.Errorf("Expected ErrNotSupported, got %v", e)
+	}
+
+	// None of the replies are delivered as events.
+	tty.inq <- []byte("a")
+	for {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); ok {
+			continue
+		}
+		if ek, ok := ev.(*EventKey); !ok || ek.Rune() != 'a' {
+			t.Errorf("Expected key a, got %#v", ev)
+		}
+		break
+	}
+}
+
+func TestTtyScreenDefaultColorsConcurrent(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b]10;?", "\x1b]10;rgb:ffff/8080/0000\x1b\\"},
+		{"\x1b]11;?", "\x1b]11;rgb:1/22/333\a"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+
+	errs := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, _, e := s.QueryDefaultColors()
+			errs <- e
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if e := <-errs; e != nil {
+			t.Errorf("Failed to query colors: %v", e)
+		}
+	}
+}
+
+func TestTtyScreenQueryFullQueue(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b]10;?", "\x1b]10;rgb:ffff/8080/0000\x1b\\"},
+		{"\x1b]11;?", "\x1b]11;rgb:1/22/333\a"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+
+	// More keys than fit in the event queue, which nobody is reading.
+	keys := "abcdefghijklmnopqrstuvwxyz"
+	tty.inq <- []byte(keys)
+	time.Sleep(50 * time.Millisecond)
+
+	if _, _, e := s.QueryDefaultColors(); e != nil {
+		t.Errorf("Failed to query colors with the queue full: %v", e)
+	}
+
+	// The keys are still delivered, in order.
+	got := ""
+	for len(got) < len(keys) {
+		ev := waitEvent(t, s)
+		if ek, ok := ev.(*EventKey); ok {
+			got += string(ek.Rune())
+		}
+	}
+	if got != keys {
+		t.Errorf("Expected keys %q, got %q", keys, got)
+	}
+}
+
+func TestTtyScreenPalette(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+
+	// The terminal only answers for some of the colors.
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b]4;0;?;1;?;2;?", "\x1b]4;1;rgb:0000/0000/ffff\x1b\\\x1b]4;3;rgb:ff/ff/ff\a"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+	pal, e := s.QueryPalette()
+	if e != nil {
+		t.Fatalf("Failed to query palette: %v", e)
+	}
+	if len(pal) != 8 {
+		t.Fatalf("Bad palette length %d", len(pal))
+	}
+	if pal[1] != NewHexColor(0x0000ff) || pal[3] != NewHexColor(0xffffff) {
+		t.Errorf("Bad palette colors: %x %x", pal[1].Hex(), pal[3].Hex())
+	}
+	if pal[4] != ColorNavy.TrueColor() {
+		t.Errorf("Unreported color changed: %x", pal[4].Hex())
+	}
+
+	// Blue is now closest to color 1, rather than 4.
+	tty.Output()
+	s.SetContent(0, 0, 'x', nil, StyleDefault.Foreground(NewHexColor(0x0000ee)))
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[31m") {
+		t.Errorf("Color not mapped to queried palette: %q", out)
+	}
+
+	s.SetPaletteColor(2, NewHexColor(0x00ff00))
+	if out := tty.Output(); !strings.Contains(out, "\x1b]4;2;rgb:00/ff/00\x1b\\") {
+		t.Errorf("Palette color not set: %q", out)
+	}
+
+	// A later query does not keep what an earlier one found.
+	tty.Lock()
+	tty.replies = [][2]string{
+		{"\x1b]4;0;?", "\x1b]4;3;rgb:ff/ff/ff\a"},
+		{"\x1b[c", "\x1b[?1;2c"},
+	}
+	tty.Unlock()
+	if pal, e = s.QueryPalette(); e != nil {
+		t.Fatalf("Failed to query palette: %v", e)
+	}
+	if pal[1] != ColorMaroon.TrueColor() || pal[2] != NewHexColor(0x00ff00) {
+		t.Errorf("Unreported colors not reset: %x %x", pal[1].Hex(), pal[2].Hex())
+	}
+	tty.Output()
+
+	s.Fini()
+	if out := tty.Output(); !strings.Contains(out, "\x1b]104\x1b\\") {
+		t.Errorf("Palette not restored: %q", out)
+	}
+}
+
+func TestTtyScreenCluster(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	tty.Output()
+
+	family := "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+	s.SetContentString(0, 0, family, StyleDefault)
+	s.SetContent(1, 0, 'y', nil, StyleDefault)
+	s.SetContent(2, 0, 'x', nil, StyleDefault)
+	s.Show()
+	out := tty.Output()
+	if !strings.Contains(out, family) {
+		t.Errorf("Cluster not drawn: %q", out)
+	}
+	if strings.Contains(out, "y") || !strings.Contains(out, "x") {
+		t.Errorf("Cell after cluster not skipped: %q", out)
+	}
+}
+
+func TestTtyScreenScrollRegion(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	for y := 0; y < 24; y++ {
+		for x, r := range fmt.Sprintf("row %d", y) {
+			s.SetContent(x, y, r, nil, StyleDefault)
+		}
+	}
+	s.Show()
+	tty.Output()
+
+	s.ScrollRegion(2, 10, 1)
+	for x, r := range "new" {
+		s.SetContent(x, 10, r, nil, StyleDefault)
+	}
+	if r, _, _, _ := s.GetContent(4, 2); r != '3' {
+		t.Errorf("Contents not scrolled: %q", r)
+	}
+	s.Show()
+	out := tty.Output()
+	if !strings.Contains(out, "\x1b[3;11r\x1b[11;1H\x1bD\x1b[1;24r") {
+		t.Errorf("Region not scrolled: %q", out)
+	}
+	if strings.Contains(out, "row") || !strings.Contains(out, "new") {
+		t.Errorf("Wrong lines redrawn: %q", out)
+	}
+
+	s.ScrollRegion(0, 23, -2)
+	s.Show()
+	out = tty.Output()
+	if !strings.Contains(out, "\x1b[1;24r\x1b[1;1H\x1bM\x1bM\x1b[1;24r") {
+		t.Errorf("Screen not scrolled down: %q", out)
+	}
+	if strings.Contains(out, "row") {
+		t.Errorf("Wrong lines redrawn: %q", out)
+	}
+}
+
+func setRow(s Screen, y int, text string, style Style) {
+	w, _ := s.Size()
+	for x := 0; x < w; x++ {
+		r := ' '
+		if x < len(text) {
+			r = rune(text[x])
+		}
+		s.SetContent(x, y, r, nil, style)
+	}
+}
+
+func TestTtyScreenErase(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	red := StyleDefault.Background(ColorRed)
+	for y := 0; y < 3; y++ {
+		setRow(s, y, strings.Repeat("x", 80), StyleDefault)
+	}
+	s.Show()
+	tty.Output()
+
+	setRow(s, 0, "abc", StyleDefault)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "abc\x1b[K") {
+		t.Errorf("Line not erased: %q", out)
+	}
+
+	for x := 10; x < 40; x++ {
+		s.SetContent(x, 1, ' ', nil, StyleDefault)
+	}
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[30X") {
+		t.Errorf("Characters not erased: %q", out)
+	}
+
+	// Erasing gives a red background, as xterm has bce.
+	setRow(s, 2, "", red)
+	s.Show()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[41m\x1b[K") {
+		t.Errorf("Colored blanks not erased: %q", out)
+	}
+}
+
+func TestTtyScreenEraseNoBce(t *testing.T) {
+	ti, e := terminfo.LookupTerminfo("xterm")
+	if e != nil {
+		t.Fatalf("Failed to find terminfo: %v", e)
+	}
+	nti := *ti
+	nti.Name = "tcell-test-no-bce"
+	nti.Aliases = nil
+	nti.BgColorErase = false
+	nti.RepeatChar = ""
+	terminfo.AddTerminfo(&nti)
+
+	s, tty := mkTestTtyScreen(t, nti.Name)
+	defer s.Fini()
+	setRow(s, 0, strings.Repeat("x", 80), StyleDefault)
+	s.Show()
+	tty.Output()
+
+	// Without bce, erasing would not give a red background.
+	setRow(s, 0, "", StyleDefault.Background(ColorRed))
+	s.Show()
+	if out := tty.Output(); strings.Contains(out, "\x1b[K") || !strings.Contains(out, strings.Repeat(" ", 80)) {
+		t.Errorf("Colored blanks erased: %q", out)
+	}
+}
+
+func TestTtyScreenEraseRepeat(t *testing.T) {
+	ti, e := terminfo.LookupTerminfo("xterm-256color")
+	if e != nil {
+		t.Fatalf("Failed to find terminfo: %v", e)
+	}
+	nti := *ti
+	nti.Name = "tcell-test-bce-rep"
+	nti.Aliases = nil
+	nti.BgColorErase = true
+	nti.RepeatChar = "%p1%c\x1b[%p2%{1}%-%db"
+	terminfo.AddTerminfo(&nti)
+
+	s, tty := mkTestTtyScreen(t, nti.Name)
+	defer s.Fini()
+
+	red := StyleDefault.Background(ColorRed)
+	setRow(s, 0, strings.Repeat("x", 80), StyleDefault)
+	s.Show()
+	tty.Output()
+
+	setRow(s, 0, "", red)
+	setRow(s, 1, strings.Repeat("=", 40)+"a", StyleDefault)
+	s.Show()
+	out := tty.Output()
+	if !strings.Contains(out, "\x1b[101m\x1b[K") {
+		t.Errorf("Colored blanks not erased: %q", out)
+	}
+	if !strings.Contains(out, "=\x1b[39ba") {
+		t.Errorf("Character not repeated: %q", out)
+	}
+}
+
+func TestTtyScreenInsertDelete(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+
+	setRow(s, 0, "hello world, this is a line of text", StyleDefault)
+	s.Show()
+	tty.Output()
+
+	setRow(s, 0, "hello, world, this is a line of text", StyleDefault)
+	s.Show()
+	out := tty.Output()
+	if !strings.Contains(out, "\x1b[1;6H\x1b[1@,") || strings.Contains(out, "world") {
+		t.Errorf("Characters not inserted: %q", out)
+	}
+
+	setRow(s, 0, "hello world, this is a line of text", StyleDefault)
+	s.Show()
+	out = tty.Output()
+	if !strings.Contains(out, "\x1b[1;6H\x1b[1P") || strings.Contains(out, "world") {
+		t.Errorf("Characters not deleted: %q", out)
+	}
+
+	// A short line gains nothing from shifting.
+	setRow(s, 1, "abc", StyleDefault)
+	s.Show()
+	tty.Output()
+	setRow(s, 1, "xabc", StyleDefault)
+	s.Show()
+	if out = tty.Output(); !strings.Contains(out, "xabc") {
+		t.Errorf("Short line not drawn: %q", out)
+	}
+}
+
+func TestTtyScreenFrame(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	s.BeginFrame()
+	setRow(s, 0, "first", StyleDefault)
+	s.Show()
+	s.BeginFrame()
+	setRow(s, 1, "second", StyleDefault)
+	s.Sync()
+	s.EndFrame()
+	if out := tty.Output(); out != "" {
+		t.Errorf("Drawn during frame: %q", out)
+	}
+	s.EndFrame()
+	out := tty.Output()
+	if !strings.Contains(out, "first") || !strings.Contains(out, "second") {
+		t.Errorf("Frame not drawn: %q", out)
+	}
+	if !strings.Contains(out, "\x1b[H\x1b[2J") {
+		t.Errorf("Deferred sync did not clear: %q", out)
+	}
+
+	// Once the frame has ended, Show draws immediately again.
+	setRow(s, 2, "third", StyleDefault)
+	s.Show()
+	if out = tty.Output(); !strings.Contains(out, "third") {
+		t.Errorf("Show after frame not drawn: %q", out)
+	}
+
+	// An empty frame draws nothing.
+	s.BeginFrame()
+	s.EndFrame()
+	if out = tty.Output(); out != "" {
+		t.Errorf("Empty frame drawn: %q", out)
+	}
+}
+
+func TestTtyScreenMaxFPS(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	// Changes made within a frame interval are drawn together.
+	s.SetMaxFPS(10)
+	start := time.Now()
+	for _, text := range []string{"first", "second", "third"} {
+		setRow(s, 0, text, StyleDefault)
+		s.Show()
+	}
+	var out string
+	for !strings.Contains(out, "third") {
+		if time.Since(start) > time.Second {
+			t.Fatalf("Frame not drawn: %q", out)
+		}
+		time.Sleep(time.Millisecond)
+		out += tty.Output()
+	}
+	if strings.Contains(out, "first") || strings.Contains(out, "second") {
+		t.Errorf("Frames not coalesced: %q", out)
+	}
+
+	// The next frame waits for the interval to pass.
+	start = time.Now()
+	setRow(s, 1, "fourth", StyleDefault)
+	s.Show()
+	for out = ""; !strings.Contains(out, "fourth"); {
+		if time.Since(start) > time.Second {
+			t.Fatalf("Frame not drawn: %q", out)
+		}
+		time.Sleep(time.Millisecond)
+		out += tty.Output()
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("Frame drawn after %v, sooner than the interval", d)
+	}
+
+	// ShowNow draws immediately, and only what changed.
+	setRow(s, 2, "fifth", StyleDefault)
+	s.ShowNow()
+	out = tty.Output()
+	if !strings.Contains(out, "fifth") || strings.Contains(out, "fourth") {
+		t.Errorf("Frame not drawn immediately: %q", out)
+	}
+
+	// Changing the limit reschedules the frame pending.
+	s.SetMaxFPS(1)
+	setRow(s, 3, "sixth", StyleDefault)
+	s.Show()
+	if out = tty.Output(); strings.Contains(out, "sixth") {
+		t.Errorf("Frame drawn sooner than the interval: %q", out)
+	}
+	s.SetMaxFPS(0)
+	if out = tty.Output(); !strings.Contains(out, "sixth") {
+		t.Errorf("Frame not drawn when limit removed: %q", out)
+	}
+
+	// Without a limit, Show draws immediately.
+	setRow(s, 4, "seventh", StyleDefault)
+	s.Show()
+	if out = tty.Output(); !strings.Contains(out, "seventh") {
+		t.Errorf("Frame not drawn immediately: %q", out)
+	}
+}
+
+func TestTtyScreenCursorMotion(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	s.SetContent(0, 0, 'a', nil, StyleDefault)
+	s.SetContent(0, 1, 'b', nil, StyleDefault)
+	s.SetContent(10, 1, 'c', nil, StyleDefault)
+	s.SetContent(5, 3, 'd', nil, StyleDefault)
+	s.Show()
+	out := tty.Output()
+	if !strings.Contains(out, "a\n\bb\x1b[9Cc\x1b[4;6Hd") {
+		t.Errorf("Cursor motion not optimized: %q", out)
+	}
+}
+
+func TestTtyScreenStyleChanges(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	defer s.Fini()
+	s.Show()
+	tty.Output()
+
+	bold := StyleDefault.Bold(true)
+	s.SetContent(0, 0, 'a', nil, bold)
+	s.SetContent(1, 0, 'b', nil, bold.Underline(true))
+	s.SetContent(2, 0, 'c', nil, StyleDefault.Underline(true))
+	s.SetContent(3, 0, 'd', nil, StyleDefault.Underline(true).Foreground(ColorRed))
+	s.SetContent(4, 0, 'e', nil, StyleDefault.Foreground(ColorRed))
+	s.SetContent(5, 0, 'f', nil, StyleDefault)
+	s.Show()
+	out := tty.Output()
+	if !strings.Contains(out, "\x1b[1ma\x1b[4mb\x1b[22mc\x1b[31md\x1b[24me\x1b[39mf") {
+		t.Errorf("Style changes not minimal: %q", out)
+	}
+}
+
+func TestTtyScreenKittyKeyboard(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	tty.Output()
+
+	s.EnableKittyKeyboard(KeyboardDisambiguate | KeyboardReportEvents)
+	if out := tty.Output(); out != "\x1b[>3u" {
+		t.Errorf("Kitty keyboard not enabled: %q", out)
+	}
+
+	tty.inq <- []byte("\x1b[?3u\x1b[105;5u\x1b[9u\x1b[57441u\x1b[97;3:3u" +
+		"\x1b[1;2:2A\x1b[97:65;2u\x1b[15;5~")
+	type result struct {
+		key   Key
+		ch    rune
+		mod   ModMask
+		etype KeyEventType
+	}
+	expect := []result{
+		{KeyTab, '\t', ModCtrl, KeyEventPress},
+		{KeyTab, '\t', ModNone, KeyEventPress},
+		{KeyRune, 'a', ModAlt, KeyEventRelease},
+		{KeyUp, 0, ModShift, KeyEventRepeat},
+		{KeyRune, 'A', ModNone, KeyEventPress},
+		{KeyF5, 0, ModCtrl, KeyEventPress},
+	}
+	for i := 0; i < len(expect); {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); ok {
+			continue
+		}
+		ek, ok := ev.(*EventKey)
+		if !ok {
+			t.Fatalf("Expected key event, got %v", ev)
+		}
+		r := result{ek.Key(), ek.Rune(), ek.Modifiers(), ek.EventType()}
+		if ek.Key() != KeyRune && ek.Key() >= ' ' {
+			r.ch = 0
+		}
+		if r != expect[i] {
+			t.Errorf("Event %d should be %v, was %v", i, expect[i], r)
+		}
+		if i == 4 && ek.ShiftedRune() != 'A' {
+			t.Errorf("Shifted rune should be A, was %q", ek.ShiftedRune())
+		}
+		i++
+	}
+
+	s.Fini()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[<u") {
+		t.Errorf("Kitty keyboard not disabled: %q", out)
+	}
+}
+
+func TestTtyScreenModifyOtherKeys(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm")
+	tty.Output()
+
+	s.EnableModifyOtherKeys()
+	if out := tty.Output(); out != "\x1b[>4;2m" {
+		t.Errorf("modifyOtherKeys not enabled: %q", out)
+	}
+
+	tty.inq <- []byte("\x1b[27;5;13~\x1b[27;6;65~\x1b[27;5;49~\x1b[27;4;33~" +
+		"\x1b[27;2;9~\x1b[65;6u\x1b[1;5A\x1b[15;2~")
+	type result struct {
+		key Key
+		ch  rune
+		mod ModMask
+	}
+	expect := []result{
+		{KeyEnter, '\r', ModCtrl},
+		{KeyCtrlA, 1, ModCtrl | ModShift},
+		{KeyRune, '1', ModCtrl},
+		{KeyRune, '!', ModAlt},
+		{KeyBacktab, 0, ModNone},
+		{KeyCtrlA, 1, ModCtrl | ModShift},
+		{KeyUp, 0, ModCtrl},
+		{KeyF5, 0, ModShift},
+	}
+	for i := 0; i < len(expect); {
+		ev := waitEvent(t, s)
+		if _, ok := ev.(*EventResize); ok {
+			continue
+		}
+		ek, ok := ev.(*EventKey)
+		if !ok {
+			t.Fatalf("Expected key event, got %v", ev)
+		}
+		r := result{ek.Key(), ek.Rune(), ek.Modifiers()}
+		if ek.Key() != KeyRune && ek.Key() >= ' ' {
+			r.ch = 0
+		}
+		if r != expect[i] {
+			t.Errorf("Event %d should be %v, was %v", i, expect[i], r)
+		}
+		i++
+	}
+
+	s.Fini()
+	if out := tty.Output(); !strings.Contains(out, "\x1b[>4m") {
+		t.Errorf("modifyOtherKeys not reset: %q", out)
+	}
+}
+
+func TestTtyScreenDrawAllocs(t *testing.T) {
+	s, tty := mkTestTtyScreen(t, "xterm-256color")
+	defer s.Fini()
+	benchFrame(s, 0)
+	s.Show()
+	tty.Output()
+
+	// Alternate between two frames, so that the sequences are cached.
+	frame := 0
+	draw := func() {
+		frame++
+		for x, r := range "drawing\u00e9\u4e16" {
+			style := StyleDefault.Foreground(PaletteColor(frame%2 + x))
+			s.SetContent(x+frame%2, 5+frame%2, r, nil, style)
+		}
+		s.Show()
+		tty.Discard()
+	}
+	draw()
+	draw()
+	if allocs := testing.AllocsPerRun(10, draw); allocs != 0 {
+		t.Errorf("Drawing allocated %v times per frame", allocs)
+	}
+}
+
+// benchText is drawn by the benchmarks, as if it were source code in an
+// editor.
+var benchText = strings.Split(strings.Repeat(`func (t *tScreen) drawCell(x, y int) int {
+	mainc, combc, style, width := t.cells.GetContent(x, y)
+	if !t.cells.Dirty(x, y) {
+		return width
+	}
+	// draw the cell here, taking care with wide characters
+}
+
+`, 4), "\n")
+
+// benchFrame draws a frame like that of a text editor, with keywords
+// and comments highlighted, and a status line.  The frame number moves
+// the cursor line (which is highlighted) and changes the status, so
+// that each frame changes scattered cells in several styles.
+func benchFrame(s Screen, frame int) {
+	w, h := s.Size()
+	keyword := StyleDefault.Foreground(ColorYellow).Bold(true)
+	comment := StyleDefault.Foreground(ColorGreen).Italic(true)
+	status := StyleDefault.Background(ColorBlue).Foreground(ColorWhite)
+	cursor := frame % (h - 1)
+	for y := 0; y < h-1; y++ {
+		line := benchText[y%len(benchText)]
+		base := StyleDefault
+		if y == cursor {
+			base = base.Background(ColorGray)
+		}
+		_, bg, _ := base.Decompose()
+		st := base
+		for x := 0; x < w; x++ {
+			r, rest := ' ', ""
+			if x < len(line) {
+				r, rest = rune(line[x]), line[x:]
+			}
+			switch {
+			case strings.HasPrefix(rest, "func"), strings.HasPrefix(rest, "return"):
+				st = keyword.Background(bg)
+			case strings.HasPrefix(rest, "//"):
+				st = comment.Background(bg)
+			case r == ' ' && st != comment.Background(bg):
+				st = base
+			}
+			s.SetContent(x, y, r, nil, st)
+		}
+	}
+	msg := fmt.Sprintf(" line %d, frame %d", cursor+1, frame)
+	for x := 0; x < w; x++ {
+		r := ' '
+		if x < len(msg) {
+			r = rune(msg[x])
+		}
+		s.SetContent(x, h-1, r, nil, status)
+	}
+}
+
+// BenchmarkTtyScreenFrames reports the bytes sent to the terminal for
+// each frame, which is what matters on slow links.
+func BenchmarkTtyScreenFrames(b *testing.B) {
+	for _, term := range []string{"xterm", "xterm-256color"} {
+		b.Run(term, func(b *testing.B) {
+			s, tty := mkTestTtyScreen(b, term)
+			defer s.Fini()
+			benchFrame(s, 0)
+			s.Show()
+			tty.Output()
+
+			n := 0
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				benchFrame(s, i+1)
+				s.Show()
+				n += tty.Discard()
+			}
+			b.ReportMetric(float64(n)/float64(b.N), "bytes/frame")
+		})
+	}
+}
+
+// BenchmarkTtyScreenRedraw reports the cost (particularly allocations)
+// of drawing every cell of a large screen, as after Sync.
+func BenchmarkTtyScreenRedraw(b *testing.B) {
+	for _, term := range []string{"xterm", "xterm-256color"} {
+		b.Run(term, func(b *testing.B) {
+			s, tty := mkTestTtyScreenSize(b, term, 300, 100)
+			defer s.Fini()
+			benchFrame(s, 0)
+			s.Show()
+			tty.Output()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.Sync()
+				tty.Discard()
+			}
+		})
+	}
+}
+
+// BenchmarkTtyScreenLog fills a large screen with lines of text, as a
+// log viewer does, a cell at a time, or a line at a time.  The screen is
+// not shown, as that is measured by BenchmarkTtyScreenRedraw.
+func BenchmarkTtyScreenLog(b *testing.B) {
+	line := strings.Repeat("2021-06-01 12:00:00 INFO request served in 12ms ", 7)
+	bench := func(b *testing.B, put func(s Screen, y int, text string)) {
+		s, _ := mkTestTtyScreenSize(b, "xterm-256color", 300, 100)
+		defer s.Fini()
+		_, h := s.Size()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < h; y++ {
+				put(s, y, line[(i+y)%40:])
+			}
+		}
+	}
+	b.Run("SetContent", func(b *testing.B) {
+		bench(b, func(s Screen, y int, text string) {
+			w, _ := s.Size()
+			for x := 0; x < w && x < len(text); x++ {
+				s.SetContent(x, y, rune(text[x]), nil, StyleDefault)
+			}
+		})
+	})
+	b.Run("PutString", func(b *testing.B) {
+		bench(b, func(s Screen, y int, text string) {
+			s.PutString(0, y, text, StyleDefault)
+		})
+	})
+}
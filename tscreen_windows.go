@@ -21,24 +21,12 @@ package tcell
 // this all work nicely with both cygwin and Windows console, so we
 // decline to do so here.
 
-func (t *tScreen) termioInit() error {
-	return ErrNoScreen
-}
-
-func (t *tScreen) termioFini() {
-	return
-}
-
-func (t *tScreen) getWinSize() (int, int, error) {
-	return 0, 0, ErrNoScreen
+// NewDevTty is not supported on this platform, and always returns
+// ErrNoScreen.
+func NewDevTty() (Tty, error) {
+	return nil, ErrNoScreen
 }
 
 func (t *tScreen) getCharset() string {
 	return "UTF-16LE"
 }
-
-func (t *tScreen) Beep() error {
-	return ErrNoScreen
-}
-
-type termiosPrivate struct{}
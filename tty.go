@@ -0,0 +1,65 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"io"
+)
+
+// Tty is an abstraction of a terminal device (traditionally a "teletype").
+// The terminfo based screen does all of its input and output through a
+// Tty, which makes it possible to run an application over something other
+// than the process' controlling terminal -- for example an SSH channel,
+// a pseudo-terminal allocated by the application, or a network connection.
+//
+// The default implementation, returned by NewDevTty, uses /dev/tty on
+// POSIX systems.
+type Tty interface {
+	// Start is called to prepare the terminal for use by the screen.
+	// When it returns, the terminal should be in raw mode (no echo,
+	// no line editing, no signal generation, no output processing),
+	// and reads should block until at least one byte is available.
+	// Any state needed to undo this should be saved so that Stop can
+	// restore it.  Start may be called again after Stop, for example
+	// when the screen is resumed after being suspended.
+	Start() error
+
+	// Stop restores the terminal to the state it was in before Start
+	// was called.  It must also cause any Read that is blocked to return
+	// promptly (the data returned, if any, is still processed), so that
+	// the screen can stop reading input.  If that cannot be done, an
+	// error must be returned, rather than leaving the Read blocked.  Stop
+	// is called when the screen is suspended, and also before Close when
+	// the screen is finalized.
+	Stop() error
+
+	// WindowSize returns the dimensions of the terminal in character
+	// cells.  If a dimension is not known, zero may be returned for it,
+	// in which case the screen will fall back to $COLUMNS and $LINES,
+	// or to the defaults in the terminal database.
+	WindowSize() (width int, height int, err error)
+
+	// NotifyResize registers a function that should be called whenever
+	// the terminal dimensions may have changed.  (On POSIX systems, this
+	// is whenever SIGWINCH is received.)  The function may be called
+	// from any goroutine.  Passing nil removes any registration.
+	NotifyResize(cb func())
+
+	// Read, Write and Close are used for the actual data transfer, and
+	// to release the underlying resources when the screen is finalized.
+	// Close should discard any input that has not been read, so that it
+	// does not reach whatever reads the terminal next.
+	io.ReadWriteCloser
+}
@@ -0,0 +1,117 @@
+// +build freebsd netbsd openbsd dragonfly
+
+// Copyright 2019 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// devTty is the Tty implementation for the process' controlling
+// terminal, /dev/tty.
+type devTty struct {
+	in      *os.File
+	out     *os.File
+	saved   syscall.Termios
+	started bool
+	resizeNotifier
+}
+
+// NewDevTty opens /dev/tty, returning a Tty for it.  This is what a
+// terminfo screen uses by default.
+func NewDevTty() (Tty, error) {
+	var e error
+	tty := &devTty{}
+	if tty.in, e = os.OpenFile("/dev/tty", os.O_RDONLY, 0); e != nil {
+		return nil, e
+	}
+	if tty.out, e = os.OpenFile("/dev/tty", os.O_WRONLY, 0); e != nil {
+		tty.in.Close()
+		return nil, e
+	}
+	return tty, nil
+}
+
+func (tty *devTty) ioctl(ioc uintptr, arg unsafe.Pointer) error {
+	fd := uintptr(tty.out.Fd())
+	if _, _, e1 := syscall.Syscall6(syscall.SYS_IOCTL, fd, ioc, uintptr(arg), 0, 0, 0); e1 != 0 {
+		return e1
+	}
+	return nil
+}
+
+func (tty *devTty) Start() error {
+	if e := tty.ioctl(syscall.TIOCGETA, unsafe.Pointer(&tty.saved)); e != nil {
+		return e
+	}
+	tty.started = true
+
+	newtios := tty.saved
+	newtios.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK |
+		syscall.ISTRIP | syscall.INLCR | syscall.IGNCR |
+		syscall.ICRNL | syscall.IXON
+	newtios.Oflag &^= syscall.OPOST
+	newtios.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON |
+		syscall.ISIG | syscall.IEXTEN
+	newtios.Cflag &^= syscall.CSIZE | syscall.PARENB
+	newtios.Cflag |= syscall.CS8
+
+	startReading(tty.in)
+
+	return tty.ioctl(syscall.TIOCSETA, unsafe.Pointer(&newtios))
+}
+
+func (tty *devTty) Stop() error {
+	e := stopReading(tty.in)
+	if !tty.started {
+		return e
+	}
+	// Note that we do not flush pending input here, as the screen may
+	// be resumed; Close does that.
+	if e1 := tty.ioctl(syscall.TIOCSETA, unsafe.Pointer(&tty.saved)); e1 != nil {
+		e = e1
+	}
+	return e
+}
+
+func (tty *devTty) WindowSize() (int, int, error) {
+	dim := [4]uint16{}
+	if e := tty.ioctl(syscall.TIOCGWINSZ, unsafe.Pointer(&dim)); e != nil {
+		return -1, -1, e
+	}
+	return int(dim[1]), int(dim[0]), nil
+}
+
+func (tty *devTty) Read(b []byte) (int, error) {
+	return tty.in.Read(b)
+}
+
+func (tty *devTty) Write(b []byte) (int, error) {
+	return tty.out.Write(b)
+}
+
+func (tty *devTty) Close() error {
+	tty.NotifyResize(nil)
+	// Discard any input that was not read, such as late replies to our
+	// queries, so that it is not passed on to the shell.
+	if tty.started {
+		tty.ioctl(syscall.TIOCSETAF, unsafe.Pointer(&tty.saved))
+	}
+	tty.in.Close()
+	return tty.out.Close()
+}
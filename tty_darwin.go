@@ -36,39 +36,52 @@ package tcell
 // close it, it actually closes
 
 import (
-	"os/signal"
 	"syscall"
 	"unsafe"
 
 	"github.com/zyedidia/poller"
 )
 
-type termiosPrivate syscall.Termios
+// devTty is the Tty implementation for the process' controlling
+// terminal, /dev/tty.
+type devTty struct {
+	in      *poller.FD
+	out     *poller.FD
+	saved   syscall.Termios
+	started bool
+	resizeNotifier
+}
 
-func (t *tScreen) termioInit() error {
+// NewDevTty opens /dev/tty, returning a Tty for it.  This is what a
+// terminfo screen uses by default.
+func NewDevTty() (Tty, error) {
 	var e error
-	var newtios termiosPrivate
-	var fd uintptr
-	var tios uintptr
-	var ioc uintptr
-	t.tiosp = &termiosPrivate{}
-
-	if t.in, e = poller.Open("/dev/tty", poller.O_RO); e != nil {
-		goto failed
+	tty := &devTty{}
+	if tty.in, e = poller.Open("/dev/tty", poller.O_RO); e != nil {
+		return nil, e
 	}
-	if t.out, e = poller.Open("/dev/tty", poller.O_WO); e != nil {
-		goto failed
+	if tty.out, e = poller.Open("/dev/tty", poller.O_WO); e != nil {
+		tty.in.Close()
+		return nil, e
 	}
+	return tty, nil
+}
 
-	tios = uintptr(unsafe.Pointer(t.tiosp))
-	ioc = uintptr(syscall.TIOCGETA)
-	fd = uintptr(t.out.(*poller.FD).Sysfd())
-	if _, _, e1 := syscall.Syscall6(syscall.SYS_IOCTL, fd, ioc, tios, 0, 0, 0); e1 != 0 {
-		e = e1
-		goto failed
+func (tty *devTty) ioctl(ioc uintptr, arg unsafe.Pointer) error {
+	fd := uintptr(tty.out.Sysfd())
+	if _, _, e1 := syscall.Syscall6(syscall.SYS_IOCTL, fd, ioc, uintptr(arg), 0, 0, 0); e1 != 0 {
+		return e1
+	}
+	return nil
+}
+
+func (tty *devTty) Start() error {
+	if e := tty.ioctl(syscall.TIOCGETA, unsafe.Pointer(&tty.saved)); e != nil {
+		return e
 	}
+	tty.started = true
 
-	newtios = *t.tiosp
+	newtios := tty.saved
 	newtios.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK |
 		syscall.ISTRIP | syscall.INLCR | syscall.IGNCR |
 		syscall.ICRNL | syscall.IXON
@@ -78,65 +91,47 @@ func (t *tScreen) termioInit() error {
 	newtios.Cflag &^= syscall.CSIZE | syscall.PARENB
 	newtios.Cflag |= syscall.CS8
 
-	tios = uintptr(unsafe.Pointer(&newtios))
+	startReading(tty.in)
 
-	ioc = uintptr(syscall.TIOCSETA)
-	if _, _, e1 := syscall.Syscall6(syscall.SYS_IOCTL, fd, ioc, tios, 0, 0, 0); e1 != 0 {
-		e = e1
-		goto failed
-	}
-
-	signal.Notify(t.sigwinch, syscall.SIGWINCH)
-
-	if w, h, e := t.getWinSize(); e == nil && w != 0 && h != 0 {
-		t.cells.Resize(w, h)
-	}
-
-	return nil
+	return tty.ioctl(syscall.TIOCSETA, unsafe.Pointer(&newtios))
+}
 
-failed:
-	if t.in != nil {
-		t.in.(*poller.FD).Close()
+func (tty *devTty) Stop() error {
+	e := stopReading(tty.in)
+	if !tty.started {
+		return e
 	}
-	if t.out != nil {
-		t.out.(*poller.FD).Close()
+	// Note that we do not flush pending input here, as the screen may
+	// be resumed; Close does that.
+	if e1 := tty.ioctl(syscall.TIOCSETA, unsafe.Pointer(&tty.saved)); e1 != nil {
+		e = e1
 	}
 	return e
 }
 
-func (t *tScreen) termioFini() {
-
-	signal.Stop(t.sigwinch)
-
-	<-t.indoneq
-
-	if t.out != nil {
-		fd := uintptr(t.out.(*poller.FD).Sysfd())
-		ioc := uintptr(syscall.TIOCSETAF)
-		tios := uintptr(unsafe.Pointer(t.tiosp))
-		syscall.Syscall6(syscall.SYS_IOCTL, fd, ioc, tios, 0, 0, 0)
-		t.out.(*poller.FD).Close()
-	}
-
-	if t.in != nil {
-		t.in.(*poller.FD).Close()
+func (tty *devTty) WindowSize() (int, int, error) {
+	dim := [4]uint16{}
+	if e := tty.ioctl(syscall.TIOCGWINSZ, unsafe.Pointer(&dim)); e != nil {
+		return -1, -1, e
 	}
+	return int(dim[1]), int(dim[0]), nil
 }
 
-func (t *tScreen) getWinSize() (int, int, error) {
+func (tty *devTty) Read(b []byte) (int, error) {
+	return tty.in.Read(b)
+}
 
-	fd := uintptr(t.out.(*poller.FD).Sysfd())
-	dim := [4]uint16{}
-	dimp := uintptr(unsafe.Pointer(&dim))
-	ioc := uintptr(syscall.TIOCGWINSZ)
-	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL,
-		fd, ioc, dimp, 0, 0, 0); err != 0 {
-		return -1, -1, err
-	}
-	return int(dim[1]), int(dim[0]), nil
+func (tty *devTty) Write(b []byte) (int, error) {
+	return tty.out.Write(b)
 }
 
-func (t *tScreen) Beep() error {
-	t.writeString(string(byte(7)))
-	return nil
+func (tty *devTty) Close() error {
+	tty.NotifyResize(nil)
+	// Discard any input that was not read, such as late replies to our
+	// queries, so that it is not passed on to the shell.
+	if tty.started {
+		tty.ioctl(syscall.TIOCSETAF, unsafe.Pointer(&tty.saved))
+	}
+	tty.in.Close()
+	return tty.out.Close()
 }
@@ -0,0 +1,123 @@
+// +build solaris illumos
+
+// Copyright 2020 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// devTty is the Tty implementation for the process' controlling
+// terminal, /dev/tty.
+type devTty struct {
+	in    *os.File
+	out   *os.File
+	saved *unix.Termios
+	resizeNotifier
+}
+
+// NewDevTty opens /dev/tty, returning a Tty for it.  This is what a
+// terminfo screen uses by default.
+func NewDevTty() (Tty, error) {
+	var e error
+	tty := &devTty{}
+	if tty.in, e = os.OpenFile("/dev/tty", os.O_RDONLY, 0); e != nil {
+		return nil, e
+	}
+	if tty.out, e = os.OpenFile("/dev/tty", os.O_WRONLY, 0); e != nil {
+		tty.in.Close()
+		return nil, e
+	}
+	return tty, nil
+}
+
+func (tty *devTty) Start() error {
+	fd := int(tty.out.Fd())
+	tio, e := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if e != nil {
+		return e
+	}
+
+	tty.saved = tio
+
+	// make a local copy, to make it raw
+	raw := &unix.Termios{
+		Cflag: tio.Cflag,
+		Oflag: tio.Oflag,
+		Iflag: tio.Iflag,
+		Lflag: tio.Lflag,
+		Cc:    tio.Cc,
+	}
+	raw.Iflag &^= (unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.INLCR |
+		unix.IGNCR | unix.ICRNL | unix.IXON)
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= (unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN)
+	raw.Cflag &^= (unix.CSIZE | unix.PARENB)
+	raw.Cflag |= unix.CS8
+
+	// This is setup for blocking reads.  In the past we attempted to
+	// use non-blocking reads, but now a separate input loop and timer
+	// copes with the problems we had on some systems (BSD/Darwin)
+	// where close hung forever.
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+
+	startReading(tty.in)
+
+	return unix.IoctlSetTermios(fd, unix.TCSETS, raw)
+}
+
+func (tty *devTty) Stop() error {
+	e := stopReading(tty.in)
+	if tty.saved == nil {
+		return e
+	}
+	// Note that we do not flush pending input here, as the screen may
+	// be resumed; Close does that.
+	if e1 := unix.IoctlSetTermios(int(tty.out.Fd()), unix.TCSETS, tty.saved); e1 != nil {
+		e = e1
+	}
+	return e
+}
+
+func (tty *devTty) WindowSize() (int, int, error) {
+	wsz, err := unix.IoctlGetWinsize(int(tty.out.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return -1, -1, err
+	}
+	return int(wsz.Col), int(wsz.Row), nil
+}
+
+func (tty *devTty) Read(b []byte) (int, error) {
+	return tty.in.Read(b)
+}
+
+func (tty *devTty) Write(b []byte) (int, error) {
+	return tty.out.Write(b)
+}
+
+func (tty *devTty) Close() error {
+	tty.NotifyResize(nil)
+	// Discard any input that was not read, such as late replies to our
+	// queries, so that it is not passed on to the shell.
+	if tty.saved != nil {
+		unix.IoctlSetTermios(int(tty.out.Fd()), unix.TCSETSF, tty.saved)
+	}
+	tty.in.Close()
+	return tty.out.Close()
+}
@@ -0,0 +1,88 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris illumos
+
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// readDeadliner is what we need of the file a devTty reads from, which
+// is an os.File, or a poller.FD on Darwin.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// startReading clears any deadline left over from stopReading, so that
+// reads block again.  If deadlines are not supported there is none.
+func startReading(f readDeadliner) {
+	f.SetReadDeadline(time.Time{})
+}
+
+// stopReading makes any pending read return promptly, as Stop must, by
+// setting a deadline that has already passed.  If the file does not
+// support deadlines (it cannot be polled), the reader cannot be woken,
+// so an error is returned.
+func stopReading(f readDeadliner) error {
+	if e := f.SetReadDeadline(time.Now()); e != nil {
+		return fmt.Errorf("cannot interrupt tty read: %v", e)
+	}
+	return nil
+}
+
+// resizeNotifier implements NotifyResize for POSIX terminals, by
+// calling the registered function whenever SIGWINCH is received.
+type resizeNotifier struct {
+	sigq  chan os.Signal
+	stopq chan struct{}
+	sync.Mutex
+}
+
+func (rn *resizeNotifier) NotifyResize(cb func()) {
+	rn.Lock()
+	defer rn.Unlock()
+
+	if rn.sigq != nil {
+		signal.Stop(rn.sigq)
+		close(rn.stopq)
+		rn.sigq = nil
+		rn.stopq = nil
+	}
+	if cb == nil {
+		return
+	}
+
+	sigq := make(chan os.Signal, 1)
+	stopq := make(chan struct{})
+	signal.Notify(sigq, syscall.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-sigq:
+				cb()
+			case <-stopq:
+				return
+			}
+		}
+	}()
+	rn.sigq = sigq
+	rn.stopq = stopq
+}
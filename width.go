@@ -0,0 +1,149 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// WidthPolicy describes how many cells characters occupy on the screen.
+// Terminals do not all agree about this, and when the application and
+// the terminal disagree the display is garbled, so applications may need
+// to adjust it to match the terminal in use.  Applications that measure
+// text themselves should use the screen's policy to do so, so that their
+// layout agrees with what is drawn.
+//
+// The zero value measures characters by the Unicode East Asian Width
+// property, with ambiguous characters narrow and emoji wide.
+type WidthPolicy struct {
+	// AmbiguousWide makes characters of ambiguous East Asian width (such
+	// as Greek and Cyrillic letters, and many symbols) two cells wide, as
+	// terminals do when configured for CJK locales.
+	AmbiguousWide bool
+
+	// EmojiWidth is the width of emoji, and of other characters shown as
+	// emoji by a variation selector.  Zero means the usual width of two.
+	// Some older terminals draw emoji in a single cell.
+	EmojiWidth int
+
+	// Overrides gives the width of particular runes, taking precedence
+	// over everything else.  A width of zero marks a rune that is drawn
+	// over the character before it.
+	Overrides map[rune]int
+}
+
+// DefaultWidthPolicy returns the policy used by screens unless another
+// is set.  Ambiguous characters are wide if the environment (LC_ALL,
+// LC_CTYPE or LANG) names a CJK locale, or RUNEWIDTH_EASTASIAN is set
+// to 1.
+func DefaultWidthPolicy() WidthPolicy {
+	return WidthPolicy{AmbiguousWide: runewidth.DefaultCondition.EastAsianWidth}
+}
+
+var defaultWidthPolicy = DefaultWidthPolicy()
+
+// narrowCondition and wideCondition measure runes as runewidth does by
+// default, but with ambiguous characters narrow or wide respectively.
+var narrowCondition, wideCondition = widthConditions()
+
+func widthConditions() (*runewidth.Condition, *runewidth.Condition) {
+	narrow := *runewidth.DefaultCondition
+	narrow.EastAsianWidth = false
+	wide := *runewidth.DefaultCondition
+	wide.EastAsianWidth = true
+	return &narrow, &wide
+}
+
+// RuneWidth returns the width of a single rune, in cells.
+func (p *WidthPolicy) RuneWidth(r rune) int {
+	if w, ok := p.Overrides[r]; ok {
+		return w
+	}
+	if r >= ' ' && r < 0x7f {
+		return 1
+	}
+	if p.EmojiWidth > 0 && isEmoji(r) {
+		return p.EmojiWidth
+	}
+	if p.AmbiguousWide {
+		return wideCondition.RuneWidth(r)
+	}
+	return narrowCondition.RuneWidth(r)
+}
+
+// ClusterWidth returns the width of the first grapheme cluster in the
+// string, which is measured as a unit.  (Emoji sequences in particular
+// are usually much narrower than the sum of their parts.)
+func (p *WidthPolicy) ClusterWidth(s string) int {
+	cluster, _ := nextCluster(s)
+	if cluster == "" {
+		return 0
+	}
+	runes := []rune(cluster)
+	return p.clusterWidth(runes[0], runes[1:])
+}
+
+// StringWidth returns the width of the string, in cells.
+func (p *WidthPolicy) StringWidth(s string) int {
+	width := 0
+	for s != "" {
+		var cluster string
+		cluster, s = nextCluster(s)
+		runes := []rune(cluster)
+		width += p.clusterWidth(runes[0], runes[1:])
+	}
+	return width
+}
+
+// clusterWidth returns the number of cells used to display a grapheme
+// cluster, given as its base rune and the runes that follow.  The width
+// is that of the base, unless a variation selector asks for emoji (wide)
+// or text (narrow) presentation, or it is a flag.  Everything else in the
+// cluster is drawn over the base.
+func (p *WidthPolicy) clusterWidth(mainc rune, combc []rune) int {
+	width := p.RuneWidth(mainc)
+	if len(combc) == 0 {
+		return width
+	}
+	if _, ok := p.Overrides[mainc]; ok {
+		return width
+	}
+	emoji := p.EmojiWidth
+	if emoji == 0 {
+		emoji = 2
+	}
+	if isRegionalIndicator(mainc) && isRegionalIndicator(combc[0]) {
+		return emoji
+	}
+	for _, r := range combc {
+		switch r {
+		case runeVS16:
+			return emoji
+		case runeVS15:
+			return 1
+		case runeZWJ:
+			// Anything joined is drawn as part of the base.
+			return width
+		}
+	}
+	return width
+}
+
+// isEmoji returns true for runes that are shown as emoji by default.
+// This is an approximation of Emoji_Presentation, covering the blocks
+// of pictographs that terminals draw as emoji.
+func isEmoji(r rune) bool {
+	return r >= 0x1f300 && r <= 0x1faff && !(r >= 0x1f3fb && r <= 0x1f3ff)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The TCell Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use file except in compliance with the License.
+// You may obtain a copy of the license at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tcell
+
+import (
+	"testing"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+func TestWidthPolicy(t *testing.T) {
+	p := &WidthPolicy{}
+	if w := p.RuneWidth('α'); w != 1 {
+		t.Errorf("Ambiguous rune should be narrow: %d", w)
+	}
+	if w := p.StringWidth("a世\U0001f44d\U0001f3fd"); w != 5 {
+		t.Errorf("Bad string width: %d", w)
+	}
+
+	p = &WidthPolicy{
+		AmbiguousWide: true,
+		EmojiWidth:    1,
+		Overrides:     map[rune]int{'x': 2},
+	}
+	if w := p.RuneWidth('α'); w != 2 {
+		t.Errorf("Ambiguous rune should be wide: %d", w)
+	}
+	if w := p.ClusterWidth("\U0001f44d\U0001f3fd"); w != 1 {
+		t.Errorf("Emoji should be narrow: %d", w)
+	}
+	if w := p.ClusterWidth("❤️"); w != 1 {
+		t.Errorf("Emoji presentation should be narrow: %d", w)
+	}
+	if w := p.StringWidth("xy"); w != 3 {
+		t.Errorf("Override not used: %d", w)
+	}
+}
+
+func TestWidthPolicyMatchesRunewidth(t *testing.T) {
+	p := DefaultWidthPolicy()
+	for _, r := range []rune{'a', 'α', '世', '\u0301', '\u200d', '\U0001f44d', ' '} {
+		if w, rw := p.RuneWidth(r), runewidth.RuneWidth(r); w != rw {
+			t.Errorf("Width of %U is %d, but runewidth says %d", r, w, rw)
+		}
+	}
+	if n := testing.AllocsPerRun(100, func() { p.RuneWidth('世') }); n != 0 {
+		t.Errorf("RuneWidth allocates: %v", n)
+	}
+}